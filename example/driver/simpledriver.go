@@ -205,6 +205,13 @@ func (s *SimpleDriver) RemoveDevice(deviceName string, protocols map[string]cont
 	return nil
 }
 
+// Ping checks whether the Device can be reached, it is called periodically
+// when the liveness monitoring is enabled
+func (s *SimpleDriver) Ping(deviceName string, protocols map[string]contract.ProtocolProperties) error {
+	s.lc.Debug(fmt.Sprintf("Device %s is pinged", deviceName))
+	return nil
+}
+
 // Discover triggers protocol specific device discovery, which is an asynchronous operation.
 // Devices found as part of this discovery operation are written to the channel devices.
 func (s *SimpleDriver) Discover() {
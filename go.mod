@@ -1,6 +1,7 @@
 module github.com/edgexfoundry/device-sdk-go
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/OneOfOne/xxhash v1.2.6
 	github.com/edgexfoundry/go-mod-bootstrap v0.0.33
 	github.com/edgexfoundry/go-mod-core-contracts v0.1.58
@@ -0,0 +1,139 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// changeFilter decides whether a numeric reading has changed enough since the
+// last published one to be published again by an OnChange AutoEvent.
+type changeFilter struct {
+	// deadband is the minimum change, absolute or a percentage of the last
+	// published value if deadbandPercent is set
+	deadband        float64
+	deadbandPercent bool
+	// thresholds are the values whose crossing is always published
+	thresholds []float64
+}
+
+// empty tells whether the filter has no setting, in which case any change of
+// the reading is published.
+func (f changeFilter) empty() bool {
+	return f.deadband == 0 && len(f.thresholds) == 0
+}
+
+// changed compares value to the last published one.
+func (f changeFilter) changed(previous float64, value float64) bool {
+	for _, t := range f.thresholds {
+		if (previous < t) != (value < t) {
+			return true
+		}
+	}
+	if f.deadband == 0 {
+		return len(f.thresholds) == 0 && value != previous
+	}
+	limit := f.deadband
+	if f.deadbandPercent {
+		limit = math.Abs(previous) * f.deadband / 100
+	}
+	return math.Abs(value-previous) > limit
+}
+
+// merge returns f with the settings of override which are set.
+func (f changeFilter) merge(override changeFilter) changeFilter {
+	if override.deadband != 0 {
+		f.deadband = override.deadband
+		f.deadbandPercent = override.deadbandPercent
+	}
+	if len(override.thresholds) > 0 {
+		f.thresholds = override.thresholds
+	}
+	return f
+}
+
+// parseDeadband parses an absolute deadband such as "0.5", or a percentage
+// such as "2%".
+func parseDeadband(value string) (float64, bool, error) {
+	percent := strings.HasSuffix(value, "%")
+	deadband, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid deadband %q", value)
+	}
+	if deadband < 0 {
+		return 0, false, fmt.Errorf("negative deadband %q", value)
+	}
+	return deadband, percent, nil
+}
+
+// parseThresholds parses comma separated values such as "10,20.5".
+func parseThresholds(value string) ([]float64, error) {
+	var thresholds []float64
+	for _, s := range strings.Split(value, ",") {
+		t, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q", s)
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+// resourceChangeFilter returns the filter defined by the attributes of a
+// device resource.
+func resourceChangeFilter(attributes map[string]string) (changeFilter, error) {
+	var f changeFilter
+	var err error
+	if value, ok := attributes[common.DeadbandAttribute]; ok {
+		if f.deadband, f.deadbandPercent, err = parseDeadband(value); err != nil {
+			return f, err
+		}
+	}
+	if value, ok := attributes[common.ThresholdAttribute]; ok {
+		if f.thresholds, err = parseThresholds(value); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// readingNumber returns the value of a numeric reading.
+func readingNumber(r contract.Reading) (float64, bool) {
+	switch r.ValueType {
+	case contract.ValueTypeUint8, contract.ValueTypeUint16, contract.ValueTypeUint32, contract.ValueTypeUint64:
+		v, err := strconv.ParseUint(r.Value, 10, 64)
+		return float64(v), err == nil
+	case contract.ValueTypeInt8, contract.ValueTypeInt16, contract.ValueTypeInt32, contract.ValueTypeInt64:
+		v, err := strconv.ParseInt(r.Value, 10, 64)
+		return float64(v), err == nil
+	case contract.ValueTypeFloat32, contract.ValueTypeFloat64:
+		if r.FloatEncoding == contract.ENotation {
+			v, err := strconv.ParseFloat(r.Value, 64)
+			return v, err == nil
+		}
+		data, err := base64.StdEncoding.DecodeString(r.Value)
+		if err != nil {
+			return 0, false
+		}
+		switch len(data) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), true
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(data)), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,124 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+)
+
+func float32Reading(name string, value float32) contract.Reading {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, math.Float32bits(value))
+	return contract.Reading{Name: name, ValueType: contract.ValueTypeFloat32, FloatEncoding: contract.Base64Encoding,
+		Value: base64.StdEncoding.EncodeToString(data)}
+}
+
+func int32Reading(name string, value string) contract.Reading {
+	return contract.Reading{Name: name, ValueType: contract.ValueTypeInt32, Value: value}
+}
+
+func initChangeCache(t *testing.T) {
+	common.LoggingClient = logger.NewMockClient()
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	cache.InitCache()
+
+	profile := contract.DeviceProfile{
+		Name: "Change-Profile",
+		DeviceResources: []contract.DeviceResource{
+			{Name: "Temperature"},
+			{Name: "Pressure", Attributes: map[string]string{common.DeadbandAttribute: "10%", common.ThresholdAttribute: "200"}},
+		},
+	}
+	_ = cache.Profiles().RemoveByName(profile.Name)
+	require.NoError(t, cache.Profiles().Add(profile))
+	_ = cache.Devices().RemoveByName("Change-Device")
+	require.NoError(t, cache.Devices().Add(contract.Device{Name: "Change-Device", Profile: profile}))
+	common.ClearDisabledBy("Change-Device")
+}
+
+func TestReadingNumber(t *testing.T) {
+	v, ok := readingNumber(float32Reading("F", 21.5))
+	assert.True(t, ok)
+	assert.Equal(t, 21.5, v)
+
+	v, ok = readingNumber(contract.Reading{ValueType: contract.ValueTypeFloat64, FloatEncoding: contract.ENotation, Value: "2.500000e+00"})
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, v)
+
+	v, ok = readingNumber(int32Reading("I", "-7"))
+	assert.True(t, ok)
+	assert.Equal(t, float64(-7), v)
+
+	_, ok = readingNumber(contract.Reading{ValueType: contract.ValueTypeString, Value: "7"})
+	assert.False(t, ok)
+}
+
+func TestChangeFilter(t *testing.T) {
+	f := changeFilter{deadband: 0.5}
+	assert.False(t, f.changed(20, 20.4))
+	assert.True(t, f.changed(20, 20.6))
+	assert.True(t, f.changed(20, 19.4))
+
+	f = changeFilter{deadband: 10, deadbandPercent: true}
+	assert.False(t, f.changed(200, 219))
+	assert.True(t, f.changed(200, 221))
+
+	f = changeFilter{thresholds: []float64{50}}
+	assert.False(t, f.changed(10, 40))
+	assert.True(t, f.changed(40, 50))
+	assert.True(t, f.changed(60, 49))
+}
+
+func TestPublishable(t *testing.T) {
+	initChangeCache(t)
+
+	defer func(config *common.ConfigurationStruct) {
+		common.CurrentConfig = config
+	}(common.CurrentConfig)
+	common.CurrentConfig = &common.ConfigurationStruct{Device: common.DeviceInfo{AutoEventSchedules: []common.AutoEventScheduleInfo{
+		{Device: "Change-Device", Deadband: "1", MinInterval: "2s", Heartbeat: "1m"},
+	}}}
+	e, err := NewExecutor("Change-Device", contract.AutoEvent{Frequency: "1s", OnChange: true})
+	require.NoError(t, err)
+	exec := e.(*executor)
+
+	start := time.Now()
+	assert.True(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 20)}, false, start))
+	// within the deadband
+	assert.False(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 20.8)}, false, start.Add(5*time.Second)))
+	// slow drift is measured against the last published value
+	assert.True(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 21.5)}, false, start.Add(10*time.Second)))
+	// too soon after the last event
+	assert.False(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 30)}, false, start.Add(11*time.Second)))
+	assert.True(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 30)}, false, start.Add(12*time.Second)))
+	// heartbeat
+	assert.False(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 30)}, false, start.Add(time.Minute)))
+	assert.True(t, exec.publishable([]contract.Reading{float32Reading("Temperature", 30)}, false, start.Add(72*time.Second)))
+
+	// the resource attributes override the deadband of the AutoEvent
+	assert.True(t, exec.publishable([]contract.Reading{int32Reading("Pressure", "150")}, false, start.Add(80*time.Second)))
+	assert.False(t, exec.publishable([]contract.Reading{int32Reading("Pressure", "164")}, false, start.Add(90*time.Second)))
+	assert.True(t, exec.publishable([]contract.Reading{int32Reading("Pressure", "166")}, false, start.Add(100*time.Second)))
+	assert.True(t, exec.publishable([]contract.Reading{int32Reading("Pressure", "195")}, false, start.Add(110*time.Second)))
+	// crossing the threshold is published despite the deadband
+	assert.True(t, exec.publishable([]contract.Reading{int32Reading("Pressure", "201")}, false, start.Add(120*time.Second)))
+}
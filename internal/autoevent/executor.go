@@ -9,10 +9,12 @@ package autoevent
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/OneOfOne/xxhash"
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
@@ -20,121 +22,443 @@ import (
 )
 
 type Executor interface {
-	Run(ctx context.Context, wg *sync.WaitGroup)
-	Stop()
+	// Start runs this Executor in its own goroutine, registered in wg, until
+	// ctx is done or Stop is called.
+	Start(ctx context.Context, wg *sync.WaitGroup)
+	// Stop cancels the scheduled executions immediately, and waits for the
+	// execution in progress, if any, to complete if wait is set.
+	Stop(wait bool)
+	// Pause skips the scheduled executions until Resume is called.
+	Pause()
+	Resume()
+	// Trigger executes the AutoEvent immediately, publishing the event even
+	// if the readings haven't changed.
+	Trigger(ctx context.Context) (*dsModels.Event, common.AppError)
+	Status() dsModels.AutoEventStatus
 }
 
 type executor struct {
 	deviceName   string
 	autoEvent    contract.AutoEvent
 	lastReadings map[string]interface{}
-	duration     time.Duration
-	stop         bool
-	rwmutex      sync.RWMutex
+	schedule     Schedule
+	jitter       time.Duration
+	filter       changeFilter
+	minInterval  time.Duration
+	heartbeat    time.Duration
+	// filters caches the change filters of the resources read
+	filters map[string]changeFilter
+	// lastValues holds the last published values of the numeric readings
+	lastValues  map[string]float64
+	lastPublish time.Time
+	paused      bool
+	nextRun     time.Time
+	lastRun     time.Time
+	lastResult  string
+	lastError   string
+	// health is the policy applied on consecutive read failures
+	health   HealthPolicy
+	failures int
+	// cancel stops the goroutine started by Start, which closes done when
+	// it returns
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stopped bool
+	rwmutex sync.RWMutex
 }
 
-// Run triggers this Executor executes the handler for the resource periodically
-func (e *executor) Run(ctx context.Context, wg *sync.WaitGroup) {
+// Start runs this Executor in its own goroutine, registered in wg, until ctx
+// is done or Stop is called
+func (e *executor) Start(ctx context.Context, wg *sync.WaitGroup) {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	if e.stopped || e.done != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	e.cancel = cancel
+	e.done = done
+	// the goroutine is registered before Start returns so that waiting on wg
+	// can't miss it
 	wg.Add(1)
-	defer wg.Done()
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		defer cancel()
+		e.run(ctx, runCtx)
+	}()
+}
+
+// Stop cancels the scheduled executions immediately, and waits for the
+// execution in progress, if any, to complete if wait is set
+func (e *executor) Stop(wait bool) {
+	e.rwmutex.Lock()
+	e.stopped = true
+	cancel, done := e.cancel, e.done
+	e.rwmutex.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	if wait {
+		<-done
+	}
+}
+
+// run executes the handler for the resource at the times of its schedule
+// until runCtx is done. The executions use ctx, so that an execution in
+// progress isn't interrupted by Stop.
+func (e *executor) run(ctx context.Context, runCtx context.Context) {
+	now := time.Now()
+	next := e.schedule.Next(now)
+	if next.IsZero() {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - schedule %s of resource %s never triggers", e.autoEvent.Frequency, e.autoEvent.Resource))
+		return
+	}
+	e.setNextRun(next)
+	timer := time.NewTimer(e.delay(next, now))
+	defer timer.Stop()
 	for {
 		select {
-		case <-ctx.Done():
+		case <-runCtx.Done():
 			return
-		case <-time.After(e.duration):
-			if e.stop {
+		case <-timer.C:
+			// the timer and the cancellation may be ready together
+			if runCtx.Err() != nil {
 				return
 			}
 
-			common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - executing %v", e.autoEvent))
-			evt, appErr := readResource(e)
-			if appErr != nil {
-				common.LoggingClient.Error(fmt.Sprintf("AutoEvent - error occurs when reading resource %s",
-					e.autoEvent.Resource))
-				continue
+			if e.isPaused() {
+				common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - skipping paused %v", e.autoEvent))
+			} else if disabledByFailures(e.deviceName) {
+				e.probe(ctx)
+			} else if e.operatingDisabled() {
+				common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - skipping %v of disabled device", e.autoEvent))
+			} else {
+				_, _ = e.execute(ctx, false)
+				if failures := e.failureCount(); e.health.FailureThreshold > 0 && failures >= e.health.FailureThreshold {
+					disableDevice(e.deviceName, failures)
+				}
 			}
 
-			if evt != nil {
-				if e.autoEvent.OnChange {
-					if compareReadings(e, evt.Readings, evt.HasBinaryValue()) {
-						common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - readings are the same as previous one %v", e.lastReadings))
-						continue
-					}
-				}
-				if evt.HasBinaryValue() {
-					common.LoggingClient.Debug("AutoEvent - pushing CBOR event")
-				} else {
-					common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - pushing event %s", evt.String()))
-				}
-				event := &dsModels.Event{Event: evt.Event}
-				// Attach origin timestamp for events if none yet specified
-				if event.Origin == 0 {
-					event.Origin = common.GetUniqueOrigin()
+			// the next execution is computed from the scheduled time rather
+			// than the current time so that delays don't accumulate
+			now = time.Now()
+			next = e.schedule.Next(next)
+			if !next.IsZero() && !next.After(now) {
+				common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - execution of %v overran its schedule, skipping missed executions", e.autoEvent))
+				next = e.schedule.Next(now)
+			}
+			// a failing device isn't read again before the backoff elapses
+			if wait := e.retryDelay(); wait > 0 && !next.IsZero() {
+				if earliest := now.Add(wait); next.Before(earliest) {
+					next = e.schedule.Next(earliest.Add(-time.Nanosecond))
 				}
-				go common.SendEvent(event)
-			} else {
-				common.LoggingClient.Info(fmt.Sprintf("AutoEvent - no event generated when reading resource %s", e.autoEvent.Resource))
 			}
+			if next.IsZero() {
+				common.LoggingClient.Info(fmt.Sprintf("AutoEvent - schedule %s of resource %s has no more executions", e.autoEvent.Frequency, e.autoEvent.Resource))
+				e.setNextRun(next)
+				return
+			}
+			e.setNextRun(next)
+			timer.Reset(e.delay(next, now))
+		}
+	}
+}
+
+// delay returns the time to wait from now until next, plus a random jitter
+// which spreads the executions of many devices sharing the same schedule.
+func (e *executor) delay(next time.Time, now time.Time) time.Duration {
+	d := next.Sub(now)
+	if e.jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(e.jitter)))
+	}
+	return d
+}
+
+// execute reads the resource and publishes the event, unless the readings of
+// an OnChange AutoEvent haven't changed and force isn't set.
+func (e *executor) execute(ctx context.Context, force bool) (*dsModels.Event, common.AppError) {
+	common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - executing %v", e.autoEvent))
+	now := time.Now()
+	evt, appErr := readResource(ctx, e)
+	if appErr != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - error occurs when reading resource %s",
+			e.autoEvent.Resource))
+		e.setResult(now, dsModels.AutoEventFailed, appErr.Message())
+		return nil, appErr
+	}
+
+	if evt != nil {
+		if e.autoEvent.OnChange {
+			if force {
+				e.rwmutex.Lock()
+				e.markPublished(evt.Readings, evt.HasBinaryValue(), now)
+				e.rwmutex.Unlock()
+			} else if !e.publishable(evt.Readings, evt.HasBinaryValue(), now) {
+				common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - readings are the same as previous one %v", e.autoEvent))
+				e.setResult(now, dsModels.AutoEventUnchanged, "")
+				return evt, nil
+			}
+		}
+		if evt.HasBinaryValue() {
+			common.LoggingClient.Debug("AutoEvent - pushing CBOR event")
+		} else {
+			common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - pushing event %s", evt.String()))
 		}
+		event := &dsModels.Event{Event: evt.Event, Qualities: evt.Qualities}
+		// Attach origin timestamp for events if none yet specified
+		if event.Origin == 0 {
+			event.Origin = common.GetUniqueOrigin()
+		}
+		go common.SendEvent(event)
+		e.setResult(now, dsModels.AutoEventPublished, "")
+		return event, nil
+	}
+
+	common.LoggingClient.Info(fmt.Sprintf("AutoEvent - no event generated when reading resource %s", e.autoEvent.Resource))
+	e.setResult(now, dsModels.AutoEventNoEvent, "")
+	return nil, nil
+}
+
+func (e *executor) setResult(at time.Time, result string, msg string) {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	e.lastRun = at
+	e.lastResult = result
+	e.lastError = msg
+	if result == dsModels.AutoEventFailed {
+		e.failures++
+	} else {
+		e.failures = 0
+	}
+}
+
+func (e *executor) failureCount() int {
+	e.rwmutex.RLock()
+	defer e.rwmutex.RUnlock()
+	return e.failures
+}
+
+// retryDelay returns the minimum delay before the next execution, which is
+// the probe interval if the device has been disabled because of failures and
+// the backoff of the consecutive failures otherwise.
+func (e *executor) retryDelay() time.Duration {
+	if disabledByFailures(e.deviceName) {
+		return e.health.probeInterval()
 	}
+	return e.health.backoff(e.failureCount())
 }
 
-func readResource(e *executor) (*dsModels.Event, common.AppError) {
+// operatingDisabled tells whether the OperatingState of the device is DISABLED.
+func (e *executor) operatingDisabled() bool {
+	device, ok := cache.Devices().ForName(e.deviceName)
+	return ok && device.OperatingState == contract.Disabled
+}
+
+// probe reads the resource of a device disabled because of failures, and
+// enables the device again if the read succeeds. The reading isn't published.
+func (e *executor) probe(ctx context.Context) {
+	common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - probing disabled device %s", e.deviceName))
+	ctx, cancel := common.NewCommandContext(ctx, "")
+	defer cancel()
+	now := time.Now()
+	if _, appErr := handler.ProbeHandler(ctx, e.deviceName, e.autoEvent.Resource); appErr != nil {
+		common.LoggingClient.Debug(fmt.Sprintf("AutoEvent - probe of device %s failed: %s", e.deviceName, appErr.Message()))
+		e.setResult(now, dsModels.AutoEventFailed, appErr.Message())
+		return
+	}
+	e.setResult(now, dsModels.AutoEventNoEvent, "")
+	enableDevice(e.deviceName)
+}
+
+func (e *executor) setNextRun(next time.Time) {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	e.nextRun = next
+}
+
+func (e *executor) isPaused() bool {
+	e.rwmutex.RLock()
+	defer e.rwmutex.RUnlock()
+	return e.paused
+}
+
+// Pause skips the scheduled executions until Resume is called
+func (e *executor) Pause() {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	e.paused = true
+}
+
+// Resume restarts the scheduled executions
+func (e *executor) Resume() {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+	e.paused = false
+}
+
+// Trigger executes the AutoEvent immediately, even if it is paused
+func (e *executor) Trigger(ctx context.Context) (*dsModels.Event, common.AppError) {
+	return e.execute(ctx, true)
+}
+
+// Status reports the state of this Executor
+func (e *executor) Status() dsModels.AutoEventStatus {
+	e.rwmutex.RLock()
+	defer e.rwmutex.RUnlock()
+	return dsModels.AutoEventStatus{
+		Device:     e.deviceName,
+		Resource:   e.autoEvent.Resource,
+		Frequency:  e.autoEvent.Frequency,
+		OnChange:   e.autoEvent.OnChange,
+		Paused:     e.paused,
+		NextRun:    toMillis(e.nextRun),
+		LastRun:    toMillis(e.lastRun),
+		LastResult: e.lastResult,
+		LastError:  e.lastError,
+		Failures:   e.failures,
+	}
+}
+
+func toMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func readResource(ctx context.Context, e *executor) (*dsModels.Event, common.AppError) {
 	vars := make(map[string]string, 2)
 	vars[common.NameVar] = e.deviceName
 	vars[common.CommandVar] = e.autoEvent.Resource
 
-	evt, appErr := handler.CommandHandler(vars, "", common.GetCmdMethod, "")
+	ctx, cancel := common.NewCommandContext(ctx, "")
+	defer cancel()
+	evt, appErr := handler.CommandHandler(ctx, vars, "", common.GetCmdMethod, "")
 	return evt, appErr
 }
 
-func compareReadings(e *executor, readings []contract.Reading, hasBinary bool) bool {
-	var identical bool = true
-	e.rwmutex.RLock()
-	defer e.rwmutex.RUnlock()
+// publishable tells whether the readings of an OnChange AutoEvent should be
+// published at now, and records them as the last published ones if so.
+func (e *executor) publishable(readings []contract.Reading, hasBinary bool, now time.Time) bool {
+	e.rwmutex.Lock()
+	defer e.rwmutex.Unlock()
+
+	heartbeat := false
+	if !e.lastPublish.IsZero() {
+		elapsed := now.Sub(e.lastPublish)
+		if e.minInterval > 0 && elapsed < e.minInterval {
+			return false
+		}
+		heartbeat = e.heartbeat > 0 && elapsed >= e.heartbeat
+	}
+	if !e.readingsChanged(readings, hasBinary) && !heartbeat {
+		return false
+	}
+	e.markPublished(readings, hasBinary, now)
+	return true
+}
+
+// markPublished records the readings as the last published ones, the caller
+// must hold the lock.
+func (e *executor) markPublished(readings []contract.Reading, hasBinary bool, now time.Time) {
+	e.recordReadings(readings, hasBinary)
+	e.lastPublish = now
+}
+
+// readingsChanged compares the readings to the last recorded ones, the
+// caller must hold the lock.
+func (e *executor) readingsChanged(readings []contract.Reading, hasBinary bool) bool {
+	changed := false
 	for _, r := range readings {
-		switch e.lastReadings[r.Name].(type) {
+		if value, ok := readingNumber(r); ok {
+			if f := e.filterFor(r.Name); !f.empty() {
+				previous, ok := e.lastValues[r.Name]
+				if !ok || f.changed(previous, value) {
+					changed = true
+				}
+				continue
+			}
+		}
+
+		switch previous := e.lastReadings[r.Name].(type) {
 		case uint64:
-			checksum := xxhash.Checksum64(r.BinaryValue)
-			if e.lastReadings[r.Name] != checksum {
-				e.lastReadings[r.Name] = checksum
-				identical = false
+			if xxhash.Checksum64(r.BinaryValue) != previous {
+				changed = true
 			}
 		case string:
-			v, ok := e.lastReadings[r.Name]
-			if !ok || v != r.Value {
-				e.lastReadings[r.Name] = r.Value
-				identical = false
+			if r.Value != previous {
+				changed = true
 			}
 		case nil:
+			changed = true
+		default:
+			common.LoggingClient.Error(fmt.Sprintf("Error: unsupported reading type (%T) in autoevent - %v", previous, e.autoEvent))
+			changed = true
+		}
+	}
+	return changed
+}
+
+// recordReadings records the readings as the last ones, the caller must hold
+// the lock.
+func (e *executor) recordReadings(readings []contract.Reading, hasBinary bool) {
+	for _, r := range readings {
+		if value, ok := readingNumber(r); ok {
+			e.lastValues[r.Name] = value
+		}
+
+		switch e.lastReadings[r.Name].(type) {
+		case uint64:
+			e.lastReadings[r.Name] = xxhash.Checksum64(r.BinaryValue)
+		case string:
+			e.lastReadings[r.Name] = r.Value
+		default:
 			if hasBinary && len(r.BinaryValue) > 0 {
 				e.lastReadings[r.Name] = xxhash.Checksum64(r.BinaryValue)
 			} else {
 				e.lastReadings[r.Name] = r.Value
 			}
-			identical = false
-		default:
-			common.LoggingClient.Error("Error: unsupported reading type (%T) in autoevent - %v\n", e.lastReadings[r.Name], e.autoEvent)
-			identical = false
 		}
 	}
-	return identical
 }
 
-// Stop marks this Executor stopped
-func (e *executor) Stop() {
-	e.stop = true
+// filterFor returns the change filter of a resource, which is the filter of
+// the AutoEvent overridden by the attributes of the device resource.
+func (e *executor) filterFor(resource string) changeFilter {
+	if f, ok := e.filters[resource]; ok {
+		return f
+	}
+
+	f := e.filter
+	if device, ok := cache.Devices().ForName(e.deviceName); ok {
+		if dr, ok := cache.Profiles().DeviceResource(device.Profile.Name, resource); ok {
+			override, err := resourceChangeFilter(dr.Attributes)
+			if err != nil {
+				common.LoggingClient.Error(fmt.Sprintf("AutoEvent - invalid change detection attributes of resource %s: %v", resource, err))
+			} else {
+				f = f.merge(override)
+			}
+		}
+	}
+	e.filters[resource] = f
+	return f
 }
 
 // NewExecutor creates an Executor for an AutoEvent
 func NewExecutor(deviceName string, ae contract.AutoEvent) (Executor, error) {
-	// check Frequency
-	duration, err := time.ParseDuration(ae.Frequency)
+	spec, err := parseAutoEvent(deviceName, ae)
 	if err != nil {
-		common.LoggingClient.Error(fmt.Sprintf("AutoEvent Frequency %s cannot be parsed error, %v", ae.Frequency, err))
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent of device %s resource %s cannot be scheduled, %v", deviceName, ae.Resource, err))
 		return nil, err
 	}
 
-	return &executor{deviceName: deviceName, autoEvent: ae,
-		lastReadings: make(map[string]interface{}), duration: duration, stop: false}, nil
+	return &executor{deviceName: deviceName, autoEvent: ae, lastReadings: make(map[string]interface{}),
+		schedule: spec.schedule, jitter: spec.jitter, filter: spec.filter, minInterval: spec.minInterval,
+		heartbeat: spec.heartbeat, filters: make(map[string]changeFilter), lastValues: make(map[string]float64),
+		health: currentHealthPolicy()}, nil
 }
@@ -7,69 +7,94 @@
 package autoevent
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestCompareReadings(t *testing.T) {
+func TestPublishableReadings(t *testing.T) {
 	readings := make([]contract.Reading, 4)
 	readings[0] = contract.Reading{Name: "Temperature", Value: "10"}
 	readings[1] = contract.Reading{Name: "Humidity", Value: "50"}
 	readings[2] = contract.Reading{Name: "Pressure", Value: "3"}
 	readings[3] = contract.Reading{Name: "Image", BinaryValue: []byte("This is a image")}
+	now := time.Now()
 
-	autoEvent := contract.AutoEvent{Frequency: "500ms"}
+	autoEvent := contract.AutoEvent{Frequency: "500ms", OnChange: true}
 	e, err := NewExecutor("hasBinaryTrue", autoEvent)
-	if err != nil {
-		t.Errorf("Autoevent executor creation failed: %v", err)
-	}
-	resultFalse := compareReadings(e.(*executor), readings, true)
-	if resultFalse {
-		t.Error("compare readings with cache failed, the result should be false in the first place")
-	}
+	require.NoError(t, err)
+	exec := e.(*executor)
+	assert.True(t, exec.publishable(readings, true, now), "the first readings should be published")
 
 	readings[1] = contract.Reading{Name: "Humidity", Value: "51"}
-	resultFalse = compareReadings(e.(*executor), readings, true)
-	if resultFalse {
-		t.Error("compare readings with cache failed, the result should be false")
-	}
+	assert.True(t, exec.publishable(readings, true, now), "the changed readings should be published")
 
 	readings[3] = contract.Reading{Name: "Image", BinaryValue: []byte("This is not a image")}
-	resultFalse = compareReadings(e.(*executor), readings, true)
-	if resultFalse {
-		t.Error("compare readings with cache failed, the result should be false")
-	}
-
-	resultTrue := compareReadings(e.(*executor), readings, true)
-	if !resultTrue {
-		t.Error("compare readings with cache failed, the result should be true with unchanged readings")
-	}
+	assert.True(t, exec.publishable(readings, true, now), "the changed binary reading should be published")
+	assert.False(t, exec.publishable(readings, true, now), "the unchanged readings shouldn't be published")
 
 	e, err = NewExecutor("hasBinaryFalse", autoEvent)
-	if err != nil {
-		t.Errorf("Autoevent executor creation failed: %v", err)
-	}
+	require.NoError(t, err)
+	exec = e.(*executor)
 	// This scenario should not happen in real case
-	resultFalse = compareReadings(e.(*executor), readings, false)
-	if resultFalse {
-		t.Error("compare readings with cache failed, the result should be false in the first place")
-	}
+	assert.True(t, exec.publishable(readings, false, now), "the first readings should be published")
 
 	readings[0] = contract.Reading{Name: "Temperature", Value: "20"}
-	resultFalse = compareReadings(e.(*executor), readings, false)
-	if resultFalse {
-		t.Error("compare readings with cache failed, the result should be false")
-	}
+	assert.True(t, exec.publishable(readings, false, now), "the changed readings should be published")
 
 	readings[3] = contract.Reading{Name: "Image", BinaryValue: []byte("This is a image")}
-	resultTrue = compareReadings(e.(*executor), readings, false)
-	if !resultTrue {
-		t.Error("compare readings with cache failed, the result should always be true in such scenario")
-	}
+	assert.False(t, exec.publishable(readings, false, now), "the binary readings are ignored without hasBinary")
+	assert.False(t, exec.publishable(readings, false, now), "the unchanged readings shouldn't be published")
+
+	// the numeric readings are compared through the change filter of their resource
+	exec.filters = map[string]changeFilter{"Pressure": {deadband: 1}}
+	readings[2] = int32Reading("Pressure", "3")
+	assert.True(t, exec.publishable(readings, false, now), "the first numeric reading should be published")
+	readings[2] = int32Reading("Pressure", "4")
+	assert.False(t, exec.publishable(readings, false, now), "the change within the deadband shouldn't be published")
+	readings[2] = int32Reading("Pressure", "5")
+	assert.True(t, exec.publishable(readings, false, now), "the change beyond the deadband should be published")
+}
 
-	resultTrue = compareReadings(e.(*executor), readings, false)
-	if !resultTrue {
-		t.Error("compare readings with cache failed, the result should be true with unchanged readings")
+// waitGroupDone tells whether wg is drained within the timeout.
+func waitGroupDone(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
 	}
 }
+
+func TestExecutorLifecycle(t *testing.T) {
+	initChangeCache(t)
+	wg := &sync.WaitGroup{}
+
+	e, err := NewExecutor("Change-Device", contract.AutoEvent{Frequency: "1h", Resource: "Temperature"})
+	require.NoError(t, err)
+	e.Start(context.Background(), wg)
+	// Stop takes effect without waiting for the next execution
+	e.Stop(true)
+	assert.True(t, waitGroupDone(wg, time.Second))
+	// a stopped Executor can't be started again
+	e.Start(context.Background(), wg)
+	assert.True(t, waitGroupDone(wg, time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e, err = NewExecutor("Change-Device", contract.AutoEvent{Frequency: "1h", Resource: "Temperature"})
+	require.NoError(t, err)
+	e.Start(ctx, wg)
+	cancel()
+	assert.True(t, waitGroupDone(wg, time.Second))
+	e.Stop(true)
+}
@@ -0,0 +1,134 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// DefaultProbeInterval is the interval between two probes of a disabled device
+// when HealthPolicy.ProbeInterval isn't set.
+const DefaultProbeInterval = time.Minute
+
+// HealthPolicy controls how the executors react to consecutive read failures.
+type HealthPolicy struct {
+	// InitialBackoff is the minimum delay before the next execution after a
+	// failure, doubled with each consecutive failure, 0 disables the backoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the backoff, 0 means no limit.
+	MaxBackoff time.Duration
+	// FailureThreshold is the number of consecutive failures after which the
+	// device is disabled, 0 means never.
+	FailureThreshold int
+	// ProbeInterval is the interval between two reads of a disabled device.
+	ProbeInterval time.Duration
+}
+
+var (
+	policy      HealthPolicy
+	policyMutex sync.RWMutex
+
+	// disabledMutex serializes the disabling and the enabling of the devices
+	// because of read failures, which are recorded by common.SetDisabledBy
+	disabledMutex sync.Mutex
+)
+
+// SetHealthPolicy sets the policy of the executors created afterwards.
+func SetHealthPolicy(p HealthPolicy) {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+	policy = p
+}
+
+func currentHealthPolicy() HealthPolicy {
+	policyMutex.RLock()
+	defer policyMutex.RUnlock()
+	return policy
+}
+
+// backoff returns the minimum delay before the next execution after the given
+// number of consecutive failures.
+func (p HealthPolicy) backoff(failures int) time.Duration {
+	if p.InitialBackoff <= 0 || failures <= 0 {
+		return 0
+	}
+	d := p.InitialBackoff
+	for i := 1; i < failures && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+func (p HealthPolicy) probeInterval() time.Duration {
+	if p.ProbeInterval > 0 {
+		return p.ProbeInterval
+	}
+	return DefaultProbeInterval
+}
+
+// disabledByFailures tells whether the device has been disabled because of
+// read failures.
+func disabledByFailures(deviceName string) bool {
+	return common.DisabledBy(deviceName) == common.DisabledByAutoEvent
+}
+
+// disableDevice sets the OperatingState of the device to DISABLED in Core
+// Metadata and in the cache.
+func disableDevice(deviceName string, failures int) {
+	disabledMutex.Lock()
+	defer disabledMutex.Unlock()
+	if common.DisabledBy(deviceName) != "" {
+		// already disabled by this or another subsystem
+		return
+	}
+
+	if !updateOperatingState(deviceName, contract.Disabled) {
+		return
+	}
+	common.SetDisabledBy(deviceName, common.DisabledByAutoEvent)
+	common.LoggingClient.Warn(fmt.Sprintf("AutoEvent - Device %s disabled after %d consecutive read failures", deviceName, failures))
+}
+
+// enableDevice sets the OperatingState of a device disabled by disableDevice
+// back to ENABLED.
+func enableDevice(deviceName string) {
+	disabledMutex.Lock()
+	defer disabledMutex.Unlock()
+	if common.DisabledBy(deviceName) != common.DisabledByAutoEvent {
+		return
+	}
+
+	if !updateOperatingState(deviceName, contract.Enabled) {
+		return
+	}
+	common.ClearDisabledBy(deviceName)
+	common.LoggingClient.Info(fmt.Sprintf("AutoEvent - Device %s recovered and enabled", deviceName))
+}
+
+func updateOperatingState(deviceName string, state contract.OperatingState) bool {
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := common.DeviceClient.UpdateOpStateByName(ctx, deviceName, string(state)); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - updating OperatingState of Device %s to %s in Core Metadata failed: %v", deviceName, state, err))
+		return false
+	}
+	if err := cache.Devices().UpdateOperatingState(deviceName, state); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("AutoEvent - updating OperatingState of Device %s in cache failed: %v", deviceName, err))
+	}
+	return true
+}
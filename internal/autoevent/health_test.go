@@ -0,0 +1,65 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestBackoff(t *testing.T) {
+	p := HealthPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	assert.Equal(t, time.Duration(0), p.backoff(0))
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 8*time.Second, p.backoff(4))
+	assert.Equal(t, 10*time.Second, p.backoff(5))
+	assert.Equal(t, 10*time.Second, p.backoff(1000))
+
+	assert.Equal(t, time.Duration(0), HealthPolicy{}.backoff(3))
+	assert.Equal(t, DefaultProbeInterval, HealthPolicy{}.probeInterval())
+}
+
+func TestDisableEnableDevice(t *testing.T) {
+	initChangeCache(t)
+
+	disableDevice("Change-Device", 3)
+	assert.True(t, disabledByFailures("Change-Device"))
+	device, _ := cache.Devices().ForName("Change-Device")
+	assert.Equal(t, contract.OperatingState(contract.Disabled), device.OperatingState)
+
+	enableDevice("Change-Device")
+	assert.False(t, disabledByFailures("Change-Device"))
+	device, _ = cache.Devices().ForName("Change-Device")
+	assert.Equal(t, contract.OperatingState(contract.Enabled), device.OperatingState)
+}
+
+func TestExecutorFailures(t *testing.T) {
+	initChangeCache(t)
+	SetHealthPolicy(HealthPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute, FailureThreshold: 3})
+	defer SetHealthPolicy(HealthPolicy{})
+
+	e, err := NewExecutor("Change-Device", contract.AutoEvent{Frequency: "1s", Resource: "Temperature"})
+	assert.NoError(t, err)
+	exec := e.(*executor)
+
+	now := time.Now()
+	exec.setResult(now, dsModels.AutoEventFailed, "unreachable")
+	exec.setResult(now, dsModels.AutoEventFailed, "unreachable")
+	assert.Equal(t, 2, exec.Status().Failures)
+	assert.Equal(t, 2*time.Second, exec.retryDelay())
+
+	exec.setResult(now, dsModels.AutoEventPublished, "")
+	assert.Equal(t, 0, exec.Status().Failures)
+	assert.Equal(t, time.Duration(0), exec.retryDelay())
+}
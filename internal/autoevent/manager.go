@@ -9,19 +9,37 @@ package autoevent
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
 type Manager interface {
 	StartAutoEvents()
+	// StopAutoEvents stops all the AutoEvents, and waits for the executions
+	// in progress to complete.
 	StopAutoEvents()
+	// RestartForDevice replaces the AutoEvents of a Device by those in cache.
 	RestartForDevice(deviceName string)
+	// StopForDevice stops the AutoEvents of a Device without waiting for the
+	// executions in progress, which may be the origin of the call.
 	StopForDevice(deviceName string)
+	// PauseAutoEvents pauses the AutoEvents of all the Devices.
+	PauseAutoEvents()
+	// ResumeAutoEvents resumes all the AutoEvents, including those paused by Device.
+	ResumeAutoEvents()
+	PauseForDevice(deviceName string) common.AppError
+	ResumeForDevice(deviceName string) common.AppError
+	// Status reports the AutoEvents of a Device, or of all the Devices if
+	// deviceName is empty.
+	Status(deviceName string) ([]dsModels.AutoEventStatus, common.AppError)
+	// TriggerForDevice executes the AutoEvent of a Device for the resource immediately.
+	TriggerForDevice(ctx context.Context, deviceName string, resource string) (*dsModels.Event, common.AppError)
 }
 
 var (
@@ -31,7 +49,11 @@ var (
 )
 
 type manager struct {
-	execsMap  map[string][]Executor
+	execsMap map[string][]Executor
+	// pausedAll and paused record the AutoEvents paused globally and by
+	// Device, so that they stay paused when their Device is restarted
+	pausedAll bool
+	paused    map[string]bool
 	startOnce sync.Once
 	ctx       context.Context
 	wg        *sync.WaitGroup
@@ -40,8 +62,11 @@ type manager struct {
 func (m *manager) StartAutoEvents() {
 	mutex.Lock()
 	m.startOnce.Do(func() {
+		if m.ctx.Err() != nil {
+			return
+		}
 		for _, d := range cache.Devices().All() {
-			execs := triggerExecutors(d.Name, d.AutoEvents, m.ctx, m.wg)
+			execs := triggerExecutors(d.Name, d.AutoEvents, m.isPaused(d.Name), m.ctx, m.wg)
 			m.execsMap[d.Name] = execs
 		}
 	})
@@ -49,17 +74,25 @@ func (m *manager) StartAutoEvents() {
 }
 
 func (m *manager) StopAutoEvents() {
+	var stopped []Executor
 	mutex.Lock()
 	for k, v := range m.execsMap {
 		for _, e := range v {
-			e.Stop()
+			e.Stop(false)
+			stopped = append(stopped, e)
 		}
 		delete(m.execsMap, k)
 	}
 	mutex.Unlock()
+
+	// all the executors are cancelled before waiting for any of them, and the
+	// mutex isn't held while waiting so that the executions can complete
+	for _, e := range stopped {
+		e.Stop(true)
+	}
 }
 
-func triggerExecutors(deviceName string, autoEvents []contract.AutoEvent, ctx context.Context, wg *sync.WaitGroup) []Executor {
+func triggerExecutors(deviceName string, autoEvents []contract.AutoEvent, paused bool, ctx context.Context, wg *sync.WaitGroup) []Executor {
 	var execs []Executor
 	for _, autoEvent := range autoEvents {
 		exec, err := NewExecutor(deviceName, autoEvent)
@@ -68,43 +101,185 @@ func triggerExecutors(deviceName string, autoEvents []contract.AutoEvent, ctx co
 			// skip this AutoEvent if it causes error during creation
 			continue
 		}
+		if paused {
+			exec.Pause()
+		}
 		execs = append(execs, exec)
-		go exec.Run(ctx, wg)
+		exec.Start(ctx, wg)
 	}
 	return execs
 }
 
-// RestartForDevice stops all the AutoEvents of the specific Device
+// RestartForDevice replaces the AutoEvents of the specific Device by those
+// in cache
 func (m *manager) RestartForDevice(deviceName string) {
-	m.StopForDevice(deviceName)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// the old executors are stopped and the new ones started under the same
+	// lock, so that concurrent restarts can't leave executors running
+	m.stopForDevice(deviceName)
 	d, ok := cache.Devices().ForName(deviceName)
 	if !ok {
 		common.LoggingClient.Error(fmt.Sprintf("there is no Device %s in cache to start AutoEvent", deviceName))
+		return
 	}
-
-	mutex.Lock()
-	execs := triggerExecutors(deviceName, d.AutoEvents, m.ctx, m.wg)
-	m.execsMap[deviceName] = execs
-	mutex.Unlock()
+	if m.ctx.Err() != nil {
+		return
+	}
+	m.execsMap[deviceName] = triggerExecutors(deviceName, d.AutoEvents, m.isPaused(deviceName), m.ctx, m.wg)
 }
 
 // StopForDevice stops all the AutoEvents of the specific Device
 func (m *manager) StopForDevice(deviceName string) {
 	mutex.Lock()
-	execs, ok := m.execsMap[deviceName]
-	if ok {
+	defer mutex.Unlock()
+	m.stopForDevice(deviceName)
+}
+
+// stopForDevice stops the AutoEvents of a Device, the caller must hold the
+// mutex.
+func (m *manager) stopForDevice(deviceName string) {
+	for _, e := range m.execsMap[deviceName] {
+		e.Stop(false)
+	}
+	delete(m.execsMap, deviceName)
+}
+
+// isPaused tells whether the AutoEvents of a Device are paused, the caller
+// must hold the mutex.
+func (m *manager) isPaused(deviceName string) bool {
+	return m.pausedAll || m.paused[deviceName]
+}
+
+// PauseAutoEvents pauses the AutoEvents of all the Devices
+func (m *manager) PauseAutoEvents() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	m.pausedAll = true
+	for _, execs := range m.execsMap {
+		for _, e := range execs {
+			e.Pause()
+		}
+	}
+}
+
+// ResumeAutoEvents resumes all the AutoEvents, including those paused by Device
+func (m *manager) ResumeAutoEvents() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	m.pausedAll = false
+	m.paused = make(map[string]bool)
+	for _, execs := range m.execsMap {
+		for _, e := range execs {
+			e.Resume()
+		}
+	}
+}
+
+// PauseForDevice pauses the AutoEvents of the specific Device
+func (m *manager) PauseForDevice(deviceName string) common.AppError {
+	if appErr := checkDevice(deviceName); appErr != nil {
+		return appErr
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	m.paused[deviceName] = true
+	for _, e := range m.execsMap[deviceName] {
+		e.Pause()
+	}
+	return nil
+}
+
+// ResumeForDevice resumes the AutoEvents of the specific Device, unless all
+// the AutoEvents are paused
+func (m *manager) ResumeForDevice(deviceName string) common.AppError {
+	if appErr := checkDevice(deviceName); appErr != nil {
+		return appErr
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	delete(m.paused, deviceName)
+	if m.pausedAll {
+		return nil
+	}
+	for _, e := range m.execsMap[deviceName] {
+		e.Resume()
+	}
+	return nil
+}
+
+// Status reports the AutoEvents of the specific Device, or of all the Devices
+func (m *manager) Status(deviceName string) ([]dsModels.AutoEventStatus, common.AppError) {
+	if deviceName != "" {
+		if appErr := checkDevice(deviceName); appErr != nil {
+			return nil, appErr
+		}
+	}
+
+	mutex.Lock()
+	result := make([]dsModels.AutoEventStatus, 0)
+	for name, execs := range m.execsMap {
+		if deviceName != "" && name != deviceName {
+			continue
+		}
 		for _, e := range execs {
-			e.Stop()
+			result = append(result, e.Status())
 		}
-		delete(m.execsMap, deviceName)
 	}
 	mutex.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Device != result[j].Device {
+			return result[i].Device < result[j].Device
+		}
+		return result[i].Resource < result[j].Resource
+	})
+	return result, nil
+}
+
+// TriggerForDevice executes the AutoEvent of the specific Device for the
+// resource immediately, and returns the event published
+func (m *manager) TriggerForDevice(ctx context.Context, deviceName string, resource string) (*dsModels.Event, common.AppError) {
+	if appErr := checkDevice(deviceName); appErr != nil {
+		return nil, appErr
+	}
+
+	var exec Executor
+	mutex.Lock()
+	for _, e := range m.execsMap[deviceName] {
+		if e.Status().Resource == resource {
+			exec = e
+			break
+		}
+	}
+	mutex.Unlock()
+
+	if exec == nil {
+		msg := fmt.Sprintf("there is no AutoEvent for resource %s of Device %s", resource, deviceName)
+		return nil, common.NewNotFoundError(msg, fmt.Errorf(msg))
+	}
+	return exec.Trigger(ctx)
+}
+
+func checkDevice(deviceName string) common.AppError {
+	if _, ok := cache.Devices().ForName(deviceName); !ok {
+		msg := fmt.Sprintf("Device %s cannot be found in cache", deviceName)
+		return common.NewNotFoundError(msg, fmt.Errorf(msg))
+	}
+	return nil
 }
 
 // NewManager initiates the AutoEvent manager once
 func NewManager(ctx context.Context, wg *sync.WaitGroup) {
 	createOnce.Do(func() {
-		m = &manager{execsMap: make(map[string][]Executor), ctx: ctx, wg: wg}
+		m = &manager{execsMap: make(map[string][]Executor), paused: make(map[string]bool), ctx: ctx, wg: wg}
 	})
 }
 
@@ -0,0 +1,92 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func pausedStates(t *testing.T, mgr *manager) []bool {
+	status, appErr := mgr.Status("Manager-Device")
+	require.Nil(t, appErr)
+	var paused []bool
+	for _, s := range status {
+		paused = append(paused, s.Paused)
+	}
+	return paused
+}
+
+func TestManagerPauseResume(t *testing.T) {
+	initChangeCache(t)
+	profile, _ := cache.Profiles().ForName("Change-Profile")
+	_ = cache.Devices().RemoveByName("Manager-Device")
+	require.NoError(t, cache.Devices().Add(contract.Device{Name: "Manager-Device", Profile: profile, AutoEvents: []contract.AutoEvent{
+		{Frequency: "1h", Resource: "Temperature"},
+		{Frequency: "1h", Resource: "Pressure", OnChange: true},
+	}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	mgr := &manager{execsMap: make(map[string][]Executor), paused: make(map[string]bool), ctx: ctx, wg: wg}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	mgr.RestartForDevice("Manager-Device")
+	status, appErr := mgr.Status("")
+	require.Nil(t, appErr)
+	require.Len(t, status, 2)
+	assert.Equal(t, "Pressure", status[0].Resource)
+	assert.True(t, status[0].OnChange)
+	assert.Equal(t, "Temperature", status[1].Resource)
+	assert.Eventually(t, func() bool {
+		status, _ := mgr.Status("Manager-Device")
+		return status[0].NextRun > 0 && status[1].NextRun > 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.Nil(t, mgr.PauseForDevice("Manager-Device"))
+	assert.Equal(t, []bool{true, true}, pausedStates(t, mgr))
+	// the pause survives the restart of the device
+	mgr.RestartForDevice("Manager-Device")
+	assert.Equal(t, []bool{true, true}, pausedStates(t, mgr))
+	require.Nil(t, mgr.ResumeForDevice("Manager-Device"))
+	assert.Equal(t, []bool{false, false}, pausedStates(t, mgr))
+
+	mgr.PauseAutoEvents()
+	assert.Equal(t, []bool{true, true}, pausedStates(t, mgr))
+	// resuming a device doesn't override the global pause
+	require.Nil(t, mgr.ResumeForDevice("Manager-Device"))
+	assert.Equal(t, []bool{true, true}, pausedStates(t, mgr))
+	mgr.ResumeAutoEvents()
+	assert.Equal(t, []bool{false, false}, pausedStates(t, mgr))
+
+	appErr = mgr.PauseForDevice("Unknown-Device")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code())
+	_, appErr = mgr.Status("Unknown-Device")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code())
+	_, appErr = mgr.TriggerForDevice(ctx, "Manager-Device", "Humidity")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code())
+
+	mgr.StopForDevice("Manager-Device")
+	status, _ = mgr.Status("")
+	assert.Equal(t, []dsModels.AutoEventStatus{}, status)
+}
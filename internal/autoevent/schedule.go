@@ -0,0 +1,260 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// Schedule computes the times at which an AutoEvent is executed.
+type Schedule interface {
+	// Next returns the first execution time after t, or the zero time if
+	// there is none.
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule executes at a fixed interval. If aligned, the executions
+// fall on the multiples of the interval in local time, e.g. every minute on
+// the :00 second.
+type intervalSchedule struct {
+	interval time.Duration
+	align    bool
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	if !s.align {
+		return t.Add(s.interval)
+	}
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(s.interval).Add(s.interval).Add(-shift)
+}
+
+// timeWindow is a daily period of time, in local time. A window whose end is
+// before its start spans midnight.
+type timeWindow struct {
+	start, end time.Duration
+}
+
+// windowSchedule restricts the executions of a Schedule to daily time windows.
+type windowSchedule struct {
+	schedule Schedule
+	windows  []timeWindow
+}
+
+// maxWindowSkips bounds the search for an execution time inside the windows,
+// for schedules which never fall inside.
+const maxWindowSkips = 1000
+
+func (s windowSchedule) Next(t time.Time) time.Time {
+	next := s.schedule.Next(t)
+	for i := 0; i < maxWindowSkips && !next.IsZero(); i++ {
+		if s.contains(next) {
+			return next
+		}
+		next = s.schedule.Next(s.nextOpening(next).Add(-time.Nanosecond))
+	}
+	return time.Time{}
+}
+
+func (s windowSchedule) contains(t time.Time) bool {
+	sinceMidnight := t.Sub(midnight(t))
+	for _, w := range s.windows {
+		if w.start <= w.end {
+			if sinceMidnight >= w.start && sinceMidnight < w.end {
+				return true
+			}
+		} else if sinceMidnight >= w.start || sinceMidnight < w.end {
+			return true
+		}
+	}
+	return false
+}
+
+// nextOpening returns the first time after t at which a window opens.
+func (s windowSchedule) nextOpening(t time.Time) time.Time {
+	var opening time.Time
+	today := midnight(t)
+	tomorrow := time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, today.Location())
+	for _, day := range []time.Time{today, tomorrow} {
+		for _, w := range s.windows {
+			o := day.Add(w.start)
+			if o.After(t) && (opening.IsZero() || o.Before(opening)) {
+				opening = o
+			}
+		}
+	}
+	return opening
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// parseWindows parses comma separated daily windows such as "08:00-12:00,13:00-17:00".
+func parseWindows(s string) ([]timeWindow, error) {
+	var windows []timeWindow
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.Split(strings.TrimSpace(part), "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid time window %q, it should be like 08:00-17:00", part)
+		}
+		start, err := parseTimeOfDay(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimeOfDay(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("empty time window %q", part)
+		}
+		windows = append(windows, timeWindow{start: start, end: end})
+	}
+	return windows, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, it should be like 17:30", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// autoEventSpec is the schedule and the change detection of an AutoEvent.
+// The Frequency of the AutoEvent is a plain duration, which is refined by the
+// first entry of Device.AutoEventSchedules matching its device and resource:
+//
+//	[[Device.AutoEventSchedules]]
+//	  Device = 'Sensor-*'
+//	  Resource = 'Temperature'
+//	  Cron = '*/5 * * * *'
+//	  Jitter = '10s'
+//	  Windows = ['08:00-17:00']
+//	  Deadband = '0.5'
+type autoEventSpec struct {
+	schedule Schedule
+	// jitter is the maximum random delay added to each execution
+	jitter time.Duration
+	// filter applies to the numeric readings of an OnChange AutoEvent, unless
+	// overridden by the attributes of their device resource
+	filter changeFilter
+	// minInterval is the minimum time between two events of an OnChange AutoEvent
+	minInterval time.Duration
+	// heartbeat is the maximum time between two events of an OnChange
+	// AutoEvent, an unchanged reading is published after it has elapsed
+	heartbeat time.Duration
+}
+
+func parsePositiveDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("negative duration %s", value)
+	}
+	return d, err
+}
+
+// scheduleConfig returns the first entry of Device.AutoEventSchedules
+// matching the device and the resource of an AutoEvent.
+func scheduleConfig(deviceName string, resource string) (common.AutoEventScheduleInfo, bool) {
+	if common.CurrentConfig == nil {
+		return common.AutoEventScheduleInfo{}, false
+	}
+	for _, config := range common.CurrentConfig.Device.AutoEventSchedules {
+		if config.Resource != "" && config.Resource != resource {
+			continue
+		}
+		if config.Device != "" {
+			if matched, err := path.Match(config.Device, deviceName); err != nil || !matched {
+				continue
+			}
+		}
+		return config, true
+	}
+	return common.AutoEventScheduleInfo{}, false
+}
+
+// parseAutoEvent returns the spec of an AutoEvent of the device.
+func parseAutoEvent(deviceName string, ae contract.AutoEvent) (autoEventSpec, error) {
+	interval, err := time.ParseDuration(ae.Frequency)
+	if err != nil {
+		return autoEventSpec{}, fmt.Errorf("invalid frequency %q, it should be a duration such as 10s", ae.Frequency)
+	}
+	if interval <= 0 {
+		return autoEventSpec{}, fmt.Errorf("frequency %s must be positive", ae.Frequency)
+	}
+
+	config, ok := scheduleConfig(deviceName, ae.Resource)
+	if !ok {
+		return autoEventSpec{schedule: intervalSchedule{interval: interval}}, nil
+	}
+	spec, err := parseScheduleConfig(interval, config)
+	if err != nil {
+		return spec, fmt.Errorf("AutoEventSchedules entry of device %q and resource %q: %v", config.Device, config.Resource, err)
+	}
+	return spec, nil
+}
+
+// parseScheduleConfig returns the spec of an AutoEvent executed every
+// interval, refined by an entry of Device.AutoEventSchedules.
+func parseScheduleConfig(interval time.Duration, config common.AutoEventScheduleInfo) (autoEventSpec, error) {
+	var spec autoEventSpec
+	var err error
+	if config.Cron != "" {
+		if config.Align {
+			return spec, fmt.Errorf("the Align option doesn't apply to cron expressions")
+		}
+		if spec.schedule, err = parseCron(config.Cron); err != nil {
+			return spec, err
+		}
+	} else {
+		spec.schedule = intervalSchedule{interval: interval, align: config.Align}
+	}
+
+	if len(config.Windows) > 0 {
+		windows, err := parseWindows(strings.Join(config.Windows, ","))
+		if err != nil {
+			return spec, err
+		}
+		spec.schedule = windowSchedule{schedule: spec.schedule, windows: windows}
+	}
+	if config.Jitter != "" {
+		if spec.jitter, err = parsePositiveDuration(config.Jitter); err != nil {
+			return spec, fmt.Errorf("invalid Jitter: %v", err)
+		}
+	}
+	if config.Deadband != "" {
+		if spec.filter.deadband, spec.filter.deadbandPercent, err = parseDeadband(config.Deadband); err != nil {
+			return spec, err
+		}
+	}
+	if config.Threshold != "" {
+		if spec.filter.thresholds, err = parseThresholds(config.Threshold); err != nil {
+			return spec, err
+		}
+	}
+	if config.MinInterval != "" {
+		if spec.minInterval, err = parsePositiveDuration(config.MinInterval); err != nil {
+			return spec, fmt.Errorf("invalid MinInterval: %v", err)
+		}
+	}
+	if config.Heartbeat != "" {
+		if spec.heartbeat, err = parsePositiveDuration(config.Heartbeat); err != nil {
+			return spec, fmt.Errorf("invalid Heartbeat: %v", err)
+		}
+	}
+	return spec, nil
+}
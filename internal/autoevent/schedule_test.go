@@ -0,0 +1,153 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package autoevent
+
+import (
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseAutoEvent(t *testing.T) {
+	defer func(config *common.ConfigurationStruct) {
+		common.CurrentConfig = config
+	}(common.CurrentConfig)
+	common.CurrentConfig = &common.ConfigurationStruct{}
+	for _, frequency := range []string{"500ms", "1m"} {
+		_, err := parseAutoEvent("Device", contract.AutoEvent{Frequency: frequency})
+		assert.NoError(t, err, frequency)
+	}
+	for _, frequency := range []string{"", "often", "-1s", "@every 1m", "1m; align=true", "*/5 * * * *"} {
+		_, err := parseAutoEvent("Device", contract.AutoEvent{Frequency: frequency})
+		assert.Error(t, err, frequency)
+	}
+
+	// the first entry matching the device and the resource applies
+	common.CurrentConfig.Device.AutoEventSchedules = []common.AutoEventScheduleInfo{
+		{Device: "Other", Jitter: "1s"},
+		{Device: "Sensor-*", Resource: "Temperature", Jitter: "2s"},
+		{Resource: "Temperature", Jitter: "3s"},
+	}
+	spec, err := parseAutoEvent("Sensor-01", contract.AutoEvent{Frequency: "1m", Resource: "Temperature"})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, spec.jitter)
+	spec, err = parseAutoEvent("Device", contract.AutoEvent{Frequency: "1m", Resource: "Temperature"})
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, spec.jitter)
+	spec, err = parseAutoEvent("Device", contract.AutoEvent{Frequency: "1m", Resource: "Pressure"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), spec.jitter)
+}
+
+func TestParseScheduleConfig(t *testing.T) {
+	valid := []common.AutoEventScheduleInfo{
+		{Align: true},
+		{Jitter: "2s", Windows: []string{"08:00-12:00", "13:00-17:00"}},
+		{Cron: "*/5 * * * *"},
+		{Cron: "0 30 9 * * MON-FRI", Jitter: "5s"},
+		{Cron: "@hourly"},
+		{Deadband: "2%", Threshold: "80,100", MinInterval: "5s", Heartbeat: "10m"},
+	}
+	for _, config := range valid {
+		_, err := parseScheduleConfig(time.Minute, config)
+		assert.NoError(t, err, config)
+	}
+
+	invalid := []common.AutoEventScheduleInfo{
+		{Jitter: "-1s"},
+		{Windows: []string{"17:00"}},
+		{Windows: []string{"08:00-08:00"}},
+		{Cron: "*/5 * * * *", Align: true},
+		{Cron: "61 * * * *"},
+		{Cron: "* * *"},
+		{Cron: "*/0 * * * *"},
+		{Deadband: "-1"},
+		{Threshold: "high"},
+		{Heartbeat: "often"},
+	}
+	for _, config := range invalid {
+		_, err := parseScheduleConfig(time.Minute, config)
+		assert.Error(t, err, config)
+	}
+
+	spec, err := parseScheduleConfig(time.Minute, common.AutoEventScheduleInfo{Deadband: "0.5", MinInterval: "5s"})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, spec.filter.deadband)
+	assert.Equal(t, 5*time.Second, spec.minInterval)
+}
+
+func TestIntervalSchedule(t *testing.T) {
+	now := at("2020-06-01 10:15:42")
+	assert.Equal(t, at("2020-06-01 10:16:12"), intervalSchedule{interval: 30 * time.Second}.Next(now))
+	assert.Equal(t, at("2020-06-01 10:16:00"), intervalSchedule{interval: time.Minute, align: true}.Next(now))
+	assert.Equal(t, at("2020-06-01 10:30:00"), intervalSchedule{interval: 15 * time.Minute, align: true}.Next(now))
+	assert.Equal(t, at("2020-06-01 10:17:00"), intervalSchedule{interval: time.Minute, align: true}.Next(at("2020-06-01 10:16:00")))
+}
+
+func TestCronSchedule(t *testing.T) {
+	tests := []struct {
+		expr     string
+		from     string
+		expected string
+	}{
+		{"*/5 * * * *", "2020-06-01 10:12:30", "2020-06-01 10:15:00"},
+		{"*/5 * * * *", "2020-06-01 10:15:00", "2020-06-01 10:20:00"},
+		{"*/10 * * * * *", "2020-06-01 10:12:31", "2020-06-01 10:12:40"},
+		{"0 9 * * MON-FRI", "2020-06-05 10:00:00", "2020-06-08 09:00:00"},
+		{"30 2 1 * *", "2020-06-15 00:00:00", "2020-07-01 02:30:00"},
+		{"0 0 29 2 *", "2020-03-01 00:00:00", "2024-02-29 00:00:00"},
+		{"0 0 1,15 * 1", "2020-06-02 00:00:00", "2020-06-08 00:00:00"},
+		{"@hourly", "2020-12-31 23:59:59", "2021-01-01 00:00:00"},
+		{"0 12 * * 7", "2020-06-01 00:00:00", "2020-06-07 12:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := parseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, at(tt.expected), s.Next(at(tt.from)))
+		})
+	}
+
+	never, err := parseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(at("2020-01-01 00:00:00")).IsZero())
+}
+
+func TestWindowSchedule(t *testing.T) {
+	spec, err := parseScheduleConfig(time.Hour, common.AutoEventScheduleInfo{Align: true, Windows: []string{"08:00-12:00", "22:00-02:00"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		from     string
+		expected string
+	}{
+		{"2020-06-01 09:30:00", "2020-06-01 10:00:00"},
+		{"2020-06-01 11:00:00", "2020-06-01 22:00:00"},
+		{"2020-06-01 23:10:00", "2020-06-02 00:00:00"},
+		{"2020-06-02 01:00:00", "2020-06-02 08:00:00"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, at(tt.expected), spec.schedule.Next(at(tt.from)), tt.from)
+	}
+
+	spec, err = parseScheduleConfig(time.Hour, common.AutoEventScheduleInfo{Align: true, Windows: []string{"08:10-08:20"}})
+	require.NoError(t, err)
+	assert.True(t, spec.schedule.Next(at("2020-06-01 00:00:00")).IsZero())
+}
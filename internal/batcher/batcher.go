@@ -0,0 +1,271 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package batcher groups encoded events produced by autoevents, commands and
+// asynchronous readings into batches which are flushed when they reach a
+// maximum number of events, a maximum size or a maximum latency. Events of
+// different content types (CBOR and JSON) are never mixed in one batch.
+package batcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// Config contains the flush window of a Batcher.
+type Config struct {
+	// MaxEvents flushes a batch once it holds this many events, 0 means unlimited.
+	MaxEvents int
+	// MaxBytes flushes a batch once its payloads reach this size, 0 means unlimited.
+	MaxBytes int
+	// MaxLatency is the longest time an event waits in a batch before it is flushed.
+	MaxLatency time.Duration
+	// MaxQueuedBatches is the maximum number of complete batches waiting to be
+	// flushed, the oldest one being dropped when it is exceeded. 0 means 16.
+	MaxQueuedBatches int
+}
+
+// ErrQueueFull is the error reported for the events of a batch dropped
+// because too many batches were waiting to be flushed.
+var ErrQueueFull = errors.New("too many event batches waiting to be flushed, the batch was dropped")
+
+// Event is a single encoded event waiting to be delivered.
+type Event struct {
+	Device      string
+	ContentType string
+	Payload     []byte
+	// Correlation is the correlation ID of the sender of the event.
+	Correlation string
+}
+
+// Batch is a group of events sharing the same content type.
+type Batch struct {
+	ID          uint64
+	ContentType string
+	Events      []Event
+}
+
+// Result reports the outcome of the batch an event was delivered in.
+type Result struct {
+	// BatchID identifies the batch, 0 when the event was not batched.
+	BatchID uint64
+	// Events is the number of events in the batch.
+	Events int
+	// Failed is the number of events of the batch which couldn't be delivered.
+	Failed int
+	// Err is the first delivery error of the batch, nil if all events were delivered.
+	Err error
+}
+
+// Metrics is a snapshot of the batcher counters.
+type Metrics struct {
+	// Queued is the number of complete batches waiting to be flushed.
+	Queued int
+	// Batches is the number of batches flushed.
+	Batches uint64
+	// Events is the number of events of the flushed batches.
+	Events uint64
+	// Failed is the number of events which couldn't be delivered.
+	Failed uint64
+	// Dropped is the number of events of the batches dropped because
+	// MaxQueuedBatches was exceeded.
+	Dropped uint64
+	// LastError is the last delivery error, empty if there was none.
+	LastError string `json:",omitempty"`
+}
+
+// FlushFunc delivers a batch and returns one error per event, nil for each
+// event delivered successfully.
+type FlushFunc func(ctx context.Context, b Batch) []error
+
+type pendingBatch struct {
+	Batch
+	bytes   int
+	results []chan Result
+	timer   *time.Timer
+}
+
+// Batcher accumulates events and hands complete batches to a FlushFunc.
+type Batcher struct {
+	cfg     Config
+	flush   FlushFunc
+	lc      logger.LoggingClient
+	mutex   sync.Mutex
+	pending map[string]*pendingBatch // key is content type
+	sealed  []*pendingBatch
+	wake    chan struct{}
+	nextID  uint64
+	stopped bool
+	metrics Metrics
+}
+
+// New creates a Batcher which delivers batches through flush once Run is started.
+func New(cfg Config, flush FlushFunc, lc logger.LoggingClient) *Batcher {
+	if cfg.MaxLatency <= 0 {
+		cfg.MaxLatency = time.Second
+	}
+	if cfg.MaxQueuedBatches <= 0 {
+		cfg.MaxQueuedBatches = 16
+	}
+	return &Batcher{
+		cfg:     cfg,
+		flush:   flush,
+		lc:      lc,
+		pending: make(map[string]*pendingBatch),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Add queues an event in the open batch for its content type. The returned
+// channel receives the result of that batch once it has been flushed.
+func (b *Batcher) Add(e Event) <-chan Result {
+	result := make(chan Result, 1)
+
+	b.mutex.Lock()
+	if b.stopped {
+		b.mutex.Unlock()
+		b.deliver(context.Background(), &pendingBatch{Batch: Batch{ContentType: e.ContentType, Events: []Event{e}}, results: []chan Result{result}})
+		return result
+	}
+
+	pb, ok := b.pending[e.ContentType]
+	if !ok {
+		b.nextID++
+		pb = &pendingBatch{Batch: Batch{ID: b.nextID, ContentType: e.ContentType}}
+		id := pb.ID
+		pb.timer = time.AfterFunc(b.cfg.MaxLatency, func() { b.seal(e.ContentType, id) })
+		b.pending[e.ContentType] = pb
+	}
+	pb.Events = append(pb.Events, e)
+	pb.bytes += len(e.Payload)
+	pb.results = append(pb.results, result)
+
+	full := (b.cfg.MaxEvents > 0 && len(pb.Events) >= b.cfg.MaxEvents) ||
+		(b.cfg.MaxBytes > 0 && pb.bytes >= b.cfg.MaxBytes)
+	b.mutex.Unlock()
+
+	if full {
+		b.seal(e.ContentType, pb.ID)
+	}
+	return result
+}
+
+// seal closes the open batch for the content type, if it is still the one
+// identified by id, and queues it for flushing.
+func (b *Batcher) seal(contentType string, id uint64) {
+	b.mutex.Lock()
+	pb, ok := b.pending[contentType]
+	if !ok || pb.ID != id {
+		b.mutex.Unlock()
+		return
+	}
+	delete(b.pending, contentType)
+	pb.timer.Stop()
+	b.sealed = append(b.sealed, pb)
+	var dropped *pendingBatch
+	if len(b.sealed) > b.cfg.MaxQueuedBatches {
+		dropped = b.sealed[0]
+		b.sealed = b.sealed[1:]
+		b.metrics.Dropped += uint64(len(dropped.Events))
+	}
+	b.mutex.Unlock()
+
+	if dropped != nil {
+		b.lc.Error(fmt.Sprintf("Batcher: dropped batch %d with %d %s events, %d batches are waiting to be flushed", dropped.ID, len(dropped.Events), dropped.ContentType, b.cfg.MaxQueuedBatches))
+		result := Result{BatchID: dropped.ID, Events: len(dropped.Events), Failed: len(dropped.Events), Err: ErrQueueFull}
+		for _, r := range dropped.results {
+			r <- result
+		}
+	}
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run flushes sealed batches in order until ctx is cancelled, then flushes
+// whatever is still pending. The caller must Add to wg beforehand.
+func (b *Batcher) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.mutex.Lock()
+			b.stopped = true
+			remaining := b.sealed
+			b.sealed = nil
+			for k, pb := range b.pending {
+				pb.timer.Stop()
+				remaining = append(remaining, pb)
+				delete(b.pending, k)
+			}
+			b.mutex.Unlock()
+
+			for _, pb := range remaining {
+				b.deliver(context.Background(), pb)
+			}
+			return
+		case <-b.wake:
+			b.mutex.Lock()
+			sealed := b.sealed
+			b.sealed = nil
+			b.mutex.Unlock()
+
+			for _, pb := range sealed {
+				b.deliver(ctx, pb)
+			}
+		}
+	}
+}
+
+func (b *Batcher) deliver(ctx context.Context, pb *pendingBatch) {
+	errs := b.flush(ctx, pb.Batch)
+
+	result := Result{BatchID: pb.ID, Events: len(pb.Events)}
+	for _, err := range errs {
+		if err != nil {
+			result.Failed++
+			if result.Err == nil {
+				result.Err = err
+			}
+		}
+	}
+
+	b.mutex.Lock()
+	b.metrics.Batches++
+	b.metrics.Events += uint64(result.Events)
+	b.metrics.Failed += uint64(result.Failed)
+	if result.Err != nil {
+		b.metrics.LastError = result.Err.Error()
+	}
+	b.mutex.Unlock()
+
+	if result.Failed > 0 {
+		b.lc.Error(fmt.Sprintf("Batcher: %d of %d events of batch %d (%s) failed to be delivered: %v", result.Failed, result.Events, pb.ID, pb.ContentType, result.Err))
+	} else {
+		b.lc.Debug(fmt.Sprintf("Batcher: delivered batch %d with %d %s events", pb.ID, result.Events, pb.ContentType))
+	}
+
+	for _, r := range pb.results {
+		r <- result
+	}
+}
+
+// Metrics returns a snapshot of the batcher counters.
+func (b *Batcher) Metrics() Metrics {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	m := b.metrics
+	m.Queued = len(b.sealed)
+	return m
+}
@@ -0,0 +1,143 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package batcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mutex   sync.Mutex
+	batches []Batch
+}
+
+func (r *recorder) flush(ctx context.Context, b Batch) []error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.batches = append(r.batches, b)
+
+	errs := make([]error, len(b.Events))
+	for i, e := range b.Events {
+		if e.Device == "broken" {
+			errs[i] = fmt.Errorf("failed to push event")
+		}
+	}
+	return errs
+}
+
+func startBatcher(cfg Config) (*Batcher, *recorder, func()) {
+	r := &recorder{}
+	b := New(cfg, r.flush, logger.MockLogger{})
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go b.Run(ctx, &wg)
+	return b, r, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func jsonEvent(device string) Event {
+	return Event{Device: device, ContentType: clients.ContentTypeJSON, Payload: []byte("{}")}
+}
+
+func TestBatcher_FlushOnMaxEvents(t *testing.T) {
+	b, r, stop := startBatcher(Config{MaxEvents: 3, MaxLatency: time.Hour})
+	defer stop()
+
+	var results []<-chan Result
+	for i := 0; i < 3; i++ {
+		results = append(results, b.Add(jsonEvent("device")))
+	}
+	for _, ch := range results {
+		result := <-ch
+		assert.Equal(t, 3, result.Events)
+		assert.Equal(t, 0, result.Failed)
+		assert.NoError(t, result.Err)
+	}
+	require.Len(t, r.batches, 1)
+}
+
+func TestBatcher_FlushOnMaxBytes(t *testing.T) {
+	b, r, stop := startBatcher(Config{MaxBytes: 4, MaxLatency: time.Hour})
+	defer stop()
+
+	b.Add(jsonEvent("device"))
+	result := <-b.Add(jsonEvent("device"))
+	assert.Equal(t, 2, result.Events)
+	require.Len(t, r.batches, 1)
+}
+
+func TestBatcher_FlushOnMaxLatencyAndSeparateContentTypes(t *testing.T) {
+	b, r, stop := startBatcher(Config{MaxEvents: 100, MaxLatency: 10 * time.Millisecond})
+	defer stop()
+
+	jsonResult := b.Add(jsonEvent("device"))
+	cborResult := b.Add(Event{Device: "device", ContentType: clients.ContentTypeCBOR, Payload: []byte{0xa0}})
+
+	j := <-jsonResult
+	c := <-cborResult
+	assert.NotEqual(t, j.BatchID, c.BatchID)
+	assert.Equal(t, 1, j.Events)
+	assert.Equal(t, 1, c.Events)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	require.Len(t, r.batches, 2)
+	for _, batch := range r.batches {
+		for _, e := range batch.Events {
+			assert.Equal(t, batch.ContentType, e.ContentType)
+		}
+	}
+}
+
+func TestBatcher_ReportsFailures(t *testing.T) {
+	b, _, stop := startBatcher(Config{MaxEvents: 2, MaxLatency: time.Hour})
+	defer stop()
+
+	b.Add(jsonEvent("device"))
+	result := <-b.Add(jsonEvent("broken"))
+	assert.Equal(t, 2, result.Events)
+	assert.Equal(t, 1, result.Failed)
+	assert.Error(t, result.Err)
+}
+
+func TestBatcher_FlushPendingOnStop(t *testing.T) {
+	b, r, stop := startBatcher(Config{MaxEvents: 100, MaxLatency: time.Hour})
+
+	ch := b.Add(jsonEvent("device"))
+	stop()
+
+	result := <-ch
+	assert.Equal(t, 1, result.Events)
+	require.Len(t, r.batches, 1)
+}
+
+func TestBatcher_DropsOldestQueuedBatch(t *testing.T) {
+	// the batches are only sealed as the batcher isn't running
+	b := New(Config{MaxEvents: 1, MaxLatency: time.Hour, MaxQueuedBatches: 2}, (&recorder{}).flush, logger.MockLogger{})
+	first := b.Add(jsonEvent("device"))
+	b.Add(jsonEvent("device"))
+	b.Add(jsonEvent("device"))
+
+	result := <-first
+	assert.Equal(t, ErrQueueFull, result.Err)
+	assert.Equal(t, 1, result.Failed)
+	m := b.Metrics()
+	assert.Equal(t, 2, m.Queued)
+	assert.Equal(t, uint64(1), m.Dropped)
+}
@@ -12,6 +12,8 @@ import (
 	"sync"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 )
 
 var (
@@ -27,6 +29,7 @@ type DeviceCache interface {
 	Remove(id string) error
 	RemoveByName(name string) error
 	UpdateAdminState(id string, state contract.AdminState) error
+	UpdateOperatingState(name string, state contract.OperatingState) error
 }
 
 type deviceCache struct {
@@ -85,7 +88,11 @@ func (d *deviceCache) Add(device contract.Device) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.add(device)
+	if err := d.add(device); err != nil {
+		return err
+	}
+	notifyDevice(dsModels.DeviceAdded, nil, &device)
+	return nil
 }
 
 func (d *deviceCache) add(device contract.Device) error {
@@ -102,10 +109,18 @@ func (d *deviceCache) Update(device contract.Device) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	var before contract.Device
+	if name, ok := d.nameMap[device.Id]; ok {
+		before = *d.dMap[name]
+	}
 	if err := d.remove(device.Id); err != nil {
 		return err
 	}
-	return d.add(device)
+	if err := d.add(device); err != nil {
+		return err
+	}
+	notifyDevice(dsModels.DeviceUpdated, &before, &device)
+	return nil
 }
 
 // Remove removes the specified device by id from the cache.
@@ -113,7 +128,11 @@ func (d *deviceCache) Remove(id string) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.remove(id)
+	name, ok := d.nameMap[id]
+	if !ok {
+		return fmt.Errorf("device %s does not exist in cache", id)
+	}
+	return d.removeAndNotify(name)
 }
 
 func (d *deviceCache) remove(id string) error {
@@ -129,7 +148,20 @@ func (d *deviceCache) RemoveByName(name string) error {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
-	return d.removeByName(name)
+	return d.removeAndNotify(name)
+}
+
+func (d *deviceCache) removeAndNotify(name string) error {
+	device, ok := d.dMap[name]
+	if !ok {
+		return fmt.Errorf("device %s does not exist in cache", name)
+	}
+	before := *device
+	if err := d.removeByName(name); err != nil {
+		return err
+	}
+	notifyDevice(dsModels.DeviceRemoved, &before, nil)
+	return nil
 }
 
 func (d *deviceCache) removeByName(name string) error {
@@ -155,7 +187,36 @@ func (d *deviceCache) UpdateAdminState(id string, state contract.AdminState) err
 		return fmt.Errorf("device %s cannot be found in cache", id)
 	}
 
-	d.dMap[name].AdminState = state
+	device := d.dMap[name]
+	if device.AdminState == state {
+		return nil
+	}
+	before := *device
+	device.AdminState = state
+	after := *device
+	notifyDevice(dsModels.DeviceAdminStateChanged, &before, &after)
+	return nil
+}
+
+// UpdateOperatingState updates the device operating state in cache by name.
+// This method is used when the Device Service itself changes the operating
+// state of a device in Core Metadata.
+func (d *deviceCache) UpdateOperatingState(name string, state contract.OperatingState) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	device, ok := d.dMap[name]
+	if !ok {
+		return fmt.Errorf("device %s cannot be found in cache", name)
+	}
+
+	if device.OperatingState == state {
+		return nil
+	}
+	before := *device
+	device.OperatingState = state
+	after := *device
+	notifyDevice(dsModels.DeviceOperatingStateChanged, &before, &after)
 	return nil
 }
 
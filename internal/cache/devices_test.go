@@ -16,6 +16,7 @@ import (
 
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 )
 
 var ds []contract.Device
@@ -158,3 +159,54 @@ func TestDeviceCache_UpdateAdminState(t *testing.T) {
 		t.Error("succeeded in executing UpdateAdminState, but the value of AdminState was not updated")
 	}
 }
+
+func TestDeviceCache_UpdateOperatingState(t *testing.T) {
+	dc := newDeviceCache(ds)
+
+	if err := dc.UpdateOperatingState(mock.NewValidDevice.Name, contract.Disabled); err == nil {
+		t.Error("supposed to get an error when updating OperatingState of the device which doesn't exist in cache")
+	}
+	if err := dc.UpdateOperatingState(mock.ValidDeviceRandomBoolGenerator.Name, contract.Disabled); err != nil {
+		t.Error("failed to update OperatingState")
+	}
+	if ud0, _ := dc.ForName(mock.ValidDeviceRandomBoolGenerator.Name); ud0.OperatingState != contract.Disabled {
+		t.Error("succeeded in executing UpdateOperatingState, but the value of OperatingState was not updated")
+	}
+	_ = dc.UpdateOperatingState(mock.ValidDeviceRandomBoolGenerator.Name, contract.Enabled)
+}
+
+func TestDeviceCache_Notifications(t *testing.T) {
+	dc := newDeviceCache([]contract.Device{})
+	ch, cancel := common.Subscribe(10)
+	defer cancel()
+
+	device := contract.Device{Id: "notified-id", Name: "Notified-Device", AdminState: contract.Unlocked, OperatingState: contract.Enabled}
+	assert.NoError(t, dc.Add(device))
+	n := <-ch
+	assert.Equal(t, dsModels.DeviceAdded, n.Type)
+	assert.Nil(t, n.DeviceBefore)
+	assert.Equal(t, "Notified-Device", n.DeviceAfter.Name)
+
+	device.AdminState = contract.Locked
+	assert.NoError(t, dc.Update(device))
+	n = <-ch
+	assert.Equal(t, dsModels.DeviceUpdated, n.Type)
+	assert.Equal(t, contract.AdminState(contract.Unlocked), n.DeviceBefore.AdminState)
+	assert.Equal(t, contract.AdminState(contract.Locked), n.DeviceAfter.AdminState)
+	assert.Equal(t, dsModels.DeviceAdminStateChanged, (<-ch).Type)
+
+	assert.NoError(t, dc.UpdateOperatingState("Notified-Device", contract.Disabled))
+	n = <-ch
+	assert.Equal(t, dsModels.DeviceOperatingStateChanged, n.Type)
+	assert.Equal(t, contract.OperatingState(contract.Enabled), n.DeviceBefore.OperatingState)
+	assert.Equal(t, contract.OperatingState(contract.Disabled), n.DeviceAfter.OperatingState)
+	// no notification when the state doesn't change
+	assert.NoError(t, dc.UpdateOperatingState("Notified-Device", contract.Disabled))
+
+	assert.NoError(t, dc.RemoveByName("Notified-Device"))
+	n = <-ch
+	assert.Equal(t, dsModels.DeviceRemoved, n.Type)
+	assert.Equal(t, "Notified-Device", n.DeviceBefore.Name)
+	assert.Nil(t, n.DeviceAfter)
+	assert.Len(t, ch, 0)
+}
@@ -0,0 +1,64 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func timestamp() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// notifyDevice reports a change of a device, followed by the changes of its
+// states when it has been updated as a whole.
+func notifyDevice(t dsModels.NotificationType, before *contract.Device, after *contract.Device) {
+	name := deviceName(before, after)
+	now := timestamp()
+	common.Notify(dsModels.Notification{Type: t, Name: name, Timestamp: now, DeviceBefore: before, DeviceAfter: after})
+	if t != dsModels.DeviceUpdated {
+		return
+	}
+	if before.AdminState != after.AdminState {
+		common.Notify(dsModels.Notification{Type: dsModels.DeviceAdminStateChanged, Name: name, Timestamp: now, DeviceBefore: before, DeviceAfter: after})
+	}
+	if before.OperatingState != after.OperatingState {
+		common.Notify(dsModels.Notification{Type: dsModels.DeviceOperatingStateChanged, Name: name, Timestamp: now, DeviceBefore: before, DeviceAfter: after})
+	}
+}
+
+func deviceName(before *contract.Device, after *contract.Device) string {
+	if after != nil {
+		return after.Name
+	}
+	return before.Name
+}
+
+func notifyProfile(t dsModels.NotificationType, before *contract.DeviceProfile, after *contract.DeviceProfile) {
+	name := ""
+	if after != nil {
+		name = after.Name
+	} else {
+		name = before.Name
+	}
+	common.Notify(dsModels.Notification{Type: t, Name: name, Timestamp: timestamp(), ProfileBefore: before, ProfileAfter: after})
+}
+
+func notifyWatcher(t dsModels.NotificationType, before *contract.ProvisionWatcher, after *contract.ProvisionWatcher) {
+	name := ""
+	if after != nil {
+		name = after.Name
+	} else {
+		name = before.Name
+	}
+	common.Notify(dsModels.Notification{Type: t, Name: name, Timestamp: timestamp(), WatcherBefore: before, WatcherAfter: after})
+}
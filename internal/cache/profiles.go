@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -82,7 +83,11 @@ func (p *profileCache) Add(profile contract.DeviceProfile) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.add(profile)
+	if err := p.add(profile); err != nil {
+		return err
+	}
+	notifyProfile(dsModels.ProfileAdded, nil, &profile)
+	return nil
 }
 
 func (p *profileCache) add(profile contract.DeviceProfile) error {
@@ -131,17 +136,29 @@ func (p *profileCache) Update(profile contract.DeviceProfile) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	var before contract.DeviceProfile
+	if name, ok := p.nameMap[profile.Id]; ok {
+		before = p.dpMap[name]
+	}
 	if err := p.remove(profile.Id); err != nil {
 		return err
 	}
-	return p.add(profile)
+	if err := p.add(profile); err != nil {
+		return err
+	}
+	notifyProfile(dsModels.ProfileUpdated, &before, &profile)
+	return nil
 }
 
 func (p *profileCache) Remove(id string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.remove(id)
+	name, ok := p.nameMap[id]
+	if !ok {
+		return fmt.Errorf("device profile %s does not exist in cache", id)
+	}
+	return p.removeAndNotify(name)
 }
 
 func (p *profileCache) remove(id string) error {
@@ -157,7 +174,19 @@ func (p *profileCache) RemoveByName(name string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.removeByName(name)
+	return p.removeAndNotify(name)
+}
+
+func (p *profileCache) removeAndNotify(name string) error {
+	before, ok := p.dpMap[name]
+	if !ok {
+		return fmt.Errorf("device profile %s does not exist in cache", name)
+	}
+	if err := p.removeByName(name); err != nil {
+		return err
+	}
+	notifyProfile(dsModels.ProfileRemoved, &before, nil)
+	return nil
 }
 
 func (p *profileCache) removeByName(name string) error {
@@ -11,6 +11,8 @@ import (
 	"sync"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 )
 
 var (
@@ -82,7 +84,11 @@ func (p *provisionWatcherCache) Add(watcher contract.ProvisionWatcher) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.add(watcher)
+	if err := p.add(watcher); err != nil {
+		return err
+	}
+	notifyWatcher(dsModels.ProvisionWatcherAdded, nil, &watcher)
+	return nil
 }
 
 func (p *provisionWatcherCache) add(watcher contract.ProvisionWatcher) error {
@@ -99,10 +105,18 @@ func (p *provisionWatcherCache) Update(watcher contract.ProvisionWatcher) error
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	var before contract.ProvisionWatcher
+	if name, ok := p.nameMap[watcher.Id]; ok {
+		before = *p.pwMap[name]
+	}
 	if err := p.remove(watcher.Id); err != nil {
 		return err
 	}
-	return p.add(watcher)
+	if err := p.add(watcher); err != nil {
+		return err
+	}
+	notifyWatcher(dsModels.ProvisionWatcherUpdated, &before, &watcher)
+	return nil
 }
 
 // Remove removes the specified provisionwatcher by id from the cache.
@@ -110,7 +124,11 @@ func (p *provisionWatcherCache) Remove(id string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.remove(id)
+	name, ok := p.nameMap[id]
+	if !ok {
+		return fmt.Errorf("watcher %s does not exist in cache", id)
+	}
+	return p.removeAndNotify(name)
 }
 
 func (p *provisionWatcherCache) remove(id string) error {
@@ -126,7 +144,20 @@ func (p *provisionWatcherCache) RemoveByName(name string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.removeByName(name)
+	return p.removeAndNotify(name)
+}
+
+func (p *provisionWatcherCache) removeAndNotify(name string) error {
+	watcher, ok := p.pwMap[name]
+	if !ok {
+		return fmt.Errorf("watcher %s does not exist in cache", name)
+	}
+	before := *watcher
+	if err := p.removeByName(name); err != nil {
+		return err
+	}
+	notifyWatcher(dsModels.ProvisionWatcherRemoved, &before, nil)
+	return nil
 }
 
 func (p *provisionWatcherCache) removeByName(name string) error {
@@ -149,7 +180,14 @@ func (p *provisionWatcherCache) UpdateAdminState(id string, state contract.Admin
 		return fmt.Errorf("watcher %s cannot be found in cache", id)
 	}
 
-	p.pwMap[name].AdminState = state
+	watcher := p.pwMap[name]
+	if watcher.AdminState == state {
+		return nil
+	}
+	before := *watcher
+	watcher.AdminState = state
+	after := *watcher
+	notifyWatcher(dsModels.ProvisionWatcherUpdated, &before, &after)
 	return nil
 }
 
@@ -47,3 +47,12 @@ func NewBadRequestError(msg string, err error) AppError {
 func NewLockedError(msg string, err error) AppError {
 	return appError{err: err, msg: msg, code: http.StatusLocked}
 }
+
+func NewServiceUnavailableError(msg string, err error) AppError {
+	return appError{err: err, msg: msg, code: http.StatusServiceUnavailable}
+}
+
+// NewAppError creates an AppError with the given HTTP status code.
+func NewAppError(msg string, err error, code int) AppError {
+	return appError{err: err, msg: msg, code: code}
+}
@@ -9,6 +9,8 @@
 package common
 
 import (
+	"time"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 )
 
@@ -24,6 +26,7 @@ const (
 
 	BootTimeoutSecondsDefault = 30
 	BootRetrySecondsDefault   = 1
+	DrainTimeoutDefault       = 5 * time.Second
 
 	ConfigStemDevice   = "edgex/devices/"
 	ConfigMajorVersion = "1.0/"
@@ -37,12 +40,26 @@ const (
 	APIAllCommandRoute      = clients.ApiDeviceRoute + "/all/{command}"
 	APIIdCommandRoute       = clients.ApiDeviceRoute + "/{id}/{command}"
 	APINameCommandRoute     = clients.ApiDeviceRoute + "/name/{name}/{command}"
+	APILabelCommandRoute    = clients.ApiDeviceRoute + "/label/{label}/{command}"
+	APIProfileCommandRoute  = clients.ApiDeviceRoute + "/profile/{profile}/{command}"
 	APIDiscoveryRoute       = clients.ApiBase + "/discovery"
 	APITransformRoute       = clients.ApiBase + "/debug/transformData/{transformData}"
+	APIProfileValidateRoute = clients.ApiBase + "/profile/validate"
+
+	APIAutoEventsRoute             = clients.ApiBase + "/autoevents"
+	APIAutoEventsPauseRoute        = APIAutoEventsRoute + "/pause"
+	APIAutoEventsResumeRoute       = APIAutoEventsRoute + "/resume"
+	APIDeviceAutoEventsRoute       = APIAutoEventsRoute + "/name/{name}"
+	APIDeviceAutoEventsPauseRoute  = APIDeviceAutoEventsRoute + "/pause"
+	APIDeviceAutoEventsResumeRoute = APIDeviceAutoEventsRoute + "/resume"
+	APIDeviceAutoEventTriggerRoute = APIDeviceAutoEventsRoute + "/{resource}/trigger"
 
 	IdVar        string = "id"
 	NameVar      string = "name"
 	CommandVar   string = "command"
+	LabelVar     string = "label"
+	ProfileVar   string = "profile"
+	ResourceVar  string = "resource"
 	GetCmdMethod string = "get"
 	SetCmdMethod string = "set"
 
@@ -50,3 +67,65 @@ const (
 	URLRawQuery       = "urlRawQuery"
 	SDKReservedPrefix = "ds-"
 )
+
+// Attributes of a device resource which are interpreted by the SDK rather than
+// by the driver.
+const (
+	// DeadbandAttribute is the minimum change of a numeric reading which is
+	// published by OnChange AutoEvents, either absolute or a percentage such as "2%".
+	DeadbandAttribute = SDKReservedPrefix + "deadband"
+	// ThresholdAttribute is a comma separated list of values whose crossing by
+	// a numeric reading is published by OnChange AutoEvents.
+	ThresholdAttribute = SDKReservedPrefix + "threshold"
+	// AssertionPolicyAttribute overrides the Device.Assertion Policy applied
+	// when a reading fails the assertion: Disable, Drop or Mark.
+	AssertionPolicyAttribute = SDKReservedPrefix + "assertion-policy"
+	// AssertionFailuresAttribute overrides the Device.Assertion FailureThreshold.
+	AssertionFailuresAttribute = SDKReservedPrefix + "assertion-failures"
+	// ExpressionAttribute is an arithmetic expression of the variable value
+	// applied to numeric readings after the base, scale, offset, mask and
+	// shift transforms.
+	ExpressionAttribute = SDKReservedPrefix + "expression"
+	// InverseExpressionAttribute is the inverse of ExpressionAttribute, applied
+	// to the numeric parameters of write commands.
+	InverseExpressionAttribute = SDKReservedPrefix + "inverse-expression"
+	// VerifyAttribute enables the read-after-write verification of the
+	// parameters written to the resource, either "true" for all the commands
+	// writing it, or a comma separated list of the commands whose set resource
+	// operation is verified. The command of a device resource is its name.
+	VerifyAttribute = SDKReservedPrefix + "verify"
+	// VerifyToleranceAttribute is the maximum difference between a numeric
+	// parameter and the value read back, absolute or a percentage of the
+	// parameter such as "1%". Values must be equal when it isn't set.
+	VerifyToleranceAttribute = SDKReservedPrefix + "verify-tolerance"
+	// VerifyRetriesAttribute overrides the Device.WriteVerification Retries.
+	VerifyRetriesAttribute = SDKReservedPrefix + "verify-retries"
+	// VerifyIntervalAttribute overrides the Device.WriteVerification
+	// RetryInterval.
+	VerifyIntervalAttribute = SDKReservedPrefix + "verify-interval"
+)
+
+// Query parameters selecting the devices targeted by a command issued to
+// several devices, which aren't passed to the driver.
+const (
+	// LabelQueryParam is a label the devices must have, it can be repeated or
+	// be a comma separated list of labels.
+	LabelQueryParam = SDKReservedPrefix + "label"
+	// ProfileQueryParam is the name of the profile of the devices.
+	ProfileQueryParam = SDKReservedPrefix + "profile"
+	// NameQueryParam is a pattern matched by the device names, e.g. Sensor-*.
+	NameQueryParam = SDKReservedPrefix + "name"
+	// PropertyQueryParam is a protocol property the devices must have, as
+	// name:value, it can be repeated.
+	PropertyQueryParam = SDKReservedPrefix + "property"
+)
+
+// Write modes of the PUT commands, given by the ds-write-mode query parameter.
+const (
+	WriteModeQueryParam = SDKReservedPrefix + "write-mode"
+	// WriteModeDefault passes the parameters to the driver, which may fail
+	// after writing some of them.
+	WriteModeDefault = "default"
+	// WriteModeTransactional writes all the parameters or none of them.
+	WriteModeTransactional = "transactional"
+)
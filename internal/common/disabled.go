@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"sync"
+)
+
+// Subsystems of the SDK which set the OperatingState of the devices to
+// DISABLED on their own, and enable them again.
+const (
+	DisabledByAssertion = "Assertion"
+	DisabledByAutoEvent = "AutoEvent"
+	DisabledByLiveness  = "Liveness"
+)
+
+var (
+	disabledBy      = make(map[string]string)
+	disabledByMutex sync.Mutex
+)
+
+// SetDisabledBy records that the subsystem has set the OperatingState of the
+// device to DISABLED. Only that subsystem enables the device again.
+func SetDisabledBy(deviceName string, subsystem string) {
+	disabledByMutex.Lock()
+	defer disabledByMutex.Unlock()
+	disabledBy[deviceName] = subsystem
+}
+
+// DisabledBy returns the subsystem which has disabled the device, empty if
+// the device hasn't been disabled by the SDK or has been enabled since.
+func DisabledBy(deviceName string) string {
+	disabledByMutex.Lock()
+	defer disabledByMutex.Unlock()
+	return disabledBy[deviceName]
+}
+
+// ClearDisabledBy forgets the subsystem which has disabled the device, once
+// the device has been enabled or removed.
+func ClearDisabledBy(deviceName string) {
+	disabledByMutex.Lock()
+	defer disabledByMutex.Unlock()
+	delete(disabledBy, deviceName)
+}
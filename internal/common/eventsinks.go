@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"sync"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+var (
+	eventSinks      []dsModels.EventSink
+	eventSinksMutex sync.RWMutex
+)
+
+// AddEventSink registers a sink which receives every event sent by the service.
+func AddEventSink(sink dsModels.EventSink) {
+	eventSinksMutex.Lock()
+	defer eventSinksMutex.Unlock()
+
+	eventSinks = append(eventSinks, sink)
+}
+
+// EventSinks returns the registered event sinks.
+func EventSinks() []dsModels.EventSink {
+	eventSinksMutex.RLock()
+	defer eventSinksMutex.RUnlock()
+
+	sinks := make([]dsModels.EventSink, len(eventSinks))
+	copy(sinks, eventSinks)
+	return sinks
+}
+
+// ClearEventSinks unregisters all event sinks and returns them.
+func ClearEventSinks() []dsModels.EventSink {
+	eventSinksMutex.Lock()
+	defer eventSinksMutex.Unlock()
+
+	sinks := eventSinks
+	eventSinks = nil
+	return sinks
+}
@@ -7,6 +7,10 @@
 package common
 
 import (
+	"github.com/edgexfoundry/device-sdk-go/internal/batcher"
+	"github.com/edgexfoundry/device-sdk-go/internal/inflight"
+	"github.com/edgexfoundry/device-sdk-go/internal/limiter"
+	"github.com/edgexfoundry/device-sdk-go/internal/outbox"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/general"
@@ -34,4 +38,10 @@ var (
 	ValueDescriptorClient  coredata.ValueDescriptorClient
 	MetadataGeneralClient  general.GeneralClient
 	ProvisionWatcherClient metadata.ProvisionWatcherClient
+	EventOutbox            *outbox.Outbox
+	EventBatcher           *batcher.Batcher
+	EventPublisher         dsModels.EventPublisher
+	DriverLimiter          *limiter.Limiter
+	// InFlight tracks the commands and autoevents in progress per device.
+	InFlight = inflight.NewTracker()
 )
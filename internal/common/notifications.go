@@ -0,0 +1,77 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"fmt"
+	"sync"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+type subscription struct {
+	ch    chan dsModels.Notification
+	types map[dsModels.NotificationType]bool
+}
+
+var (
+	subscriptions      []*subscription
+	subscriptionsMutex sync.RWMutex
+)
+
+// Subscribe registers a channel receiving the notifications of the given
+// types, or of all the types if none is given. The returned function cancels
+// the subscription and closes the channel.
+func Subscribe(bufferSize int, types ...dsModels.NotificationType) (<-chan dsModels.Notification, func()) {
+	sub := &subscription{ch: make(chan dsModels.Notification, bufferSize)}
+	if len(types) > 0 {
+		sub.types = make(map[dsModels.NotificationType]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	subscriptionsMutex.Lock()
+	subscriptions = append(subscriptions, sub)
+	subscriptionsMutex.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			subscriptionsMutex.Lock()
+			defer subscriptionsMutex.Unlock()
+			for i, s := range subscriptions {
+				if s == sub {
+					subscriptions = append(subscriptions[:i], subscriptions[i+1:]...)
+					break
+				}
+			}
+			close(sub.ch)
+		})
+	}
+}
+
+// Notify sends the notification to the subscribers of its type. It never
+// blocks: the notification is dropped for the subscribers whose channel is
+// full.
+func Notify(n dsModels.Notification) {
+	subscriptionsMutex.RLock()
+	defer subscriptionsMutex.RUnlock()
+
+	for _, s := range subscriptions {
+		if s.types != nil && !s.types[n.Type] {
+			continue
+		}
+		select {
+		case s.ch <- n:
+		default:
+			if LoggingClient != nil {
+				LoggingClient.Warn(fmt.Sprintf("Subscriber channel is full, dropping %s notification of %s", n.Type, n.Name))
+			}
+		}
+	}
+}
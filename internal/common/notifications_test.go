@@ -0,0 +1,39 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package common
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestSubscribe(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	all, cancelAll := Subscribe(1)
+	removed, cancelRemoved := Subscribe(10, dsModels.DeviceRemoved)
+	defer cancelRemoved()
+
+	Notify(dsModels.Notification{Type: dsModels.DeviceAdded, Name: "D1"})
+	Notify(dsModels.Notification{Type: dsModels.DeviceRemoved, Name: "D1"})
+
+	// the second notification is dropped as the buffer of all is full
+	assert.Equal(t, dsModels.DeviceAdded, (<-all).Type)
+	assert.Len(t, all, 0)
+	assert.Equal(t, dsModels.DeviceRemoved, (<-removed).Type)
+	assert.Len(t, removed, 0)
+
+	cancelAll()
+	cancelAll()
+	_, open := <-all
+	assert.False(t, open)
+	Notify(dsModels.Notification{Type: dsModels.DeviceRemoved, Name: "D2"})
+	assert.Equal(t, "D2", (<-removed).Name)
+}
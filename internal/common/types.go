@@ -9,6 +9,9 @@
 package common
 
 import (
+	"github.com/edgexfoundry/device-sdk-go/internal/batcher"
+	"github.com/edgexfoundry/device-sdk-go/internal/limiter"
+	"github.com/edgexfoundry/device-sdk-go/internal/outbox"
 	bootstrapConfig "github.com/edgexfoundry/go-mod-bootstrap/config"
 	dsModels "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -81,11 +84,114 @@ type DeviceInfo struct {
 	// ProfilesDir specifies a directory which contains deviceprofile
 	// files which should be imported on startup.
 	ProfilesDir string
+	// DevicesDir specifies a directory which contains device definition
+	// files, one device per TOML or JSON file, which are created on startup
+	// in addition to the DeviceList.
+	DevicesDir string
 	// UpdateLastConnected specifies whether to update device's LastConnected
 	// timestamp in metadata.
 	UpdateLastConnected bool
+	// ReadingQuality specifies whether the readings failing a transform or an
+	// assertion keep their value, with a quality reported in the command
+	// responses, rather than having it replaced by a description of the
+	// failure. As Core Data readings have no quality, the readings of Bad
+	// quality are then withheld from the events sent to the event sinks.
+	ReadingQuality bool
+	// DrainTimeout is the longest time a device update or removal waits for
+	// the commands and autoevents in progress on the device to complete
+	// before calling the driver. It represents as a duration string.
+	DrainTimeout string
 
 	Discovery DiscoveryInfo
+	// Outbox contains the settings of the persistent event outbox.
+	Outbox OutboxInfo
+	// EventBatch contains the settings of event batching.
+	EventBatch EventBatchInfo
+	// EventSink contains the settings of the destinations events are sent to.
+	EventSink EventSinkInfo
+	// Concurrency contains the limits on concurrent driver calls.
+	Concurrency ConcurrencyInfo
+	// AutoEventHealth contains the handling of repeated AutoEvent read failures.
+	AutoEventHealth AutoEventHealthInfo
+	// Assertion contains the handling of the readings failing the assertion
+	// of their device resource.
+	Assertion AssertionInfo
+	// HotReload contains the settings of the reloading of ProfilesDir,
+	// DevicesDir and DeviceList when they change.
+	HotReload HotReloadInfo
+	// Liveness contains the settings of the monitoring of device reachability.
+	Liveness LivenessInfo
+	// CommandAll contains the settings of the commands issued to all the
+	// operational devices.
+	CommandAll CommandAllInfo
+	// WriteVerification contains the settings of the reading back of the
+	// parameters written to the device resources having the ds-verify attribute.
+	WriteVerification WriteVerificationInfo
+	// AutoEventSchedules refine the schedule and the change detection of the
+	// AutoEvents, whose Frequency is a plain duration. The first entry
+	// matching the device and the resource of an AutoEvent applies.
+	AutoEventSchedules []AutoEventScheduleInfo
+}
+
+// AutoEventScheduleInfo is a struct which contains the schedule and change
+// detection settings of the AutoEvents of some devices and resources.
+type AutoEventScheduleInfo struct {
+	// Device is the name of the devices, or a pattern such as Sensor-*.
+	// Empty matches all the devices.
+	Device string
+	// Resource is the resource of the AutoEvents, empty matches all of them.
+	Resource string
+	// Cron is a cron expression of 5 or 6 fields, or a descriptor such as
+	// @hourly, replacing the Frequency of the AutoEvents.
+	Cron string
+	// Align makes the executions fall on the multiples of the Frequency in
+	// local time, e.g. every minute on the :00 second.
+	Align bool
+	// Jitter is the maximum random delay added to each execution. It
+	// represents as a duration string.
+	Jitter string
+	// Windows are the daily time windows, such as 08:00-17:00, outside of
+	// which the executions are skipped.
+	Windows []string
+	// Deadband is the minimum change of a numeric reading published by
+	// OnChange AutoEvents, either absolute or a percentage such as "2%". It
+	// is overridden by the ds-deadband attribute of the device resource.
+	Deadband string
+	// Threshold is a comma separated list of values whose crossing by a
+	// numeric reading is published by OnChange AutoEvents. It is overridden
+	// by the ds-threshold attribute of the device resource.
+	Threshold string
+	// MinInterval is the minimum time between two events of an OnChange
+	// AutoEvent. It represents as a duration string.
+	MinInterval string
+	// Heartbeat is the maximum time between two events of an OnChange
+	// AutoEvent, after which an unchanged reading is published. It
+	// represents as a duration string.
+	Heartbeat string
+}
+
+// CommandAllInfo is a struct which contains configuration of the commands
+// issued to all the operational devices through /device/all.
+type CommandAllInfo struct {
+	// MaxWorkers is the maximum number of devices, or batches of devices,
+	// commanded concurrently, 0 means 16.
+	MaxWorkers int
+	// MaxBatchSize is the maximum number of devices passed in a single call
+	// to a driver implementing BatchProtocolDriver, 0 means unlimited.
+	MaxBatchSize int
+}
+
+// WriteVerificationInfo is a struct which contains configuration of the
+// read-after-write verification of the PUT commands. The Retries and
+// RetryInterval can be overridden by the ds-verify-retries and
+// ds-verify-interval attributes of the device resource.
+type WriteVerificationInfo struct {
+	// Retries is the number of times a value which doesn't match the written
+	// parameter is read again before the command fails.
+	Retries int
+	// RetryInterval is the time between the readings of a value. It represents
+	// as a duration string, empty means no wait.
+	RetryInterval string
 }
 
 // DiscoveryInfo is a struct which contains configuration of device auto discovery.
@@ -97,6 +203,116 @@ type DiscoveryInfo struct {
 	Interval string
 }
 
+// OutboxInfo is a struct which contains configuration of the persistent
+// store-and-forward queue used to push events to Core Data.
+type OutboxInfo struct {
+	// Enabled controls whether events are written to the outbox and forwarded
+	// in the background, instead of being pushed to Core Data directly.
+	Enabled bool
+	// Path is the directory in which queued events are stored.
+	Path string
+	// MaxEvents is the maximum number of queued events, 0 means unlimited.
+	MaxEvents int
+	// MaxBytes is the maximum disk space used by queued events, 0 means unlimited.
+	MaxBytes int64
+	// Retention is the maximum age of a queued event as a duration string.
+	// Older events are discarded without being sent. Empty means no limit.
+	Retention string
+	// OverflowPolicy decides what happens when the outbox is full. It should
+	// be 'DropOldest', 'DropNewest' or 'Block'.
+	OverflowPolicy string
+	// BlockTimeout is how long SendEvent waits for free space under the 'Block'
+	// policy before dropping the event. It represents as a duration string.
+	BlockTimeout string
+	// RetryInterval is the initial interval between attempts to forward an
+	// event while Core Data is unreachable. It represents as a duration string.
+	RetryInterval string
+	// MaxRetryInterval caps the exponential backoff of RetryInterval.
+	MaxRetryInterval string
+}
+
+// EventBatchInfo is a struct which contains configuration of event batching.
+type EventBatchInfo struct {
+	// Enabled controls whether events are grouped into batches before being
+	// pushed to Core Data.
+	Enabled bool
+	// MaxEvents flushes a batch once it holds this many events, 0 means unlimited.
+	MaxEvents int
+	// MaxBytes flushes a batch once its encoded events reach this size, 0 means unlimited.
+	MaxBytes int
+	// MaxLatency is the longest time an event waits in a batch before it is
+	// flushed. It represents as a duration string.
+	MaxLatency string
+	// MaxQueuedBatches is the maximum number of complete batches waiting to be
+	// pushed, the oldest one being dropped when it is exceeded. 0 means 16.
+	MaxQueuedBatches int
+}
+
+// EventSinkInfo is a struct which contains configuration of the event sinks.
+type EventSinkInfo struct {
+	// Sinks lists the sinks every event is sent to, any of CoreData, File
+	// and Publisher. Defaults to CoreData when empty.
+	Sinks []string
+	// FilePath is the JSON-lines file events are appended to by the File sink.
+	FilePath string
+	// PublisherTopic is the topic used by the Publisher sink, {device} is
+	// replaced with the name of the device.
+	PublisherTopic string
+}
+
+// ConcurrencyInfo is a struct which contains configuration of the limits on
+// concurrent HandleReadCommands/HandleWriteCommands calls. Calls over a limit
+// are queued until a slot is free or MaxWait has elapsed.
+type ConcurrencyInfo struct {
+	// MaxPerDevice is the maximum number of concurrent driver calls for a
+	// single device, 0 means unlimited. Use 1 for half-duplex protocols.
+	MaxPerDevice int
+	// MaxPerAddress is the maximum number of concurrent driver calls sharing
+	// the same protocol address, e.g. devices on the same serial port, 0
+	// means unlimited.
+	MaxPerAddress int
+	// AddressProperties lists the protocol properties identifying the address
+	// of a device, e.g. 'Address' for a Modbus RTU serial port.
+	AddressProperties []string
+	// MaxWait is the longest time a driver call waits in the queue before
+	// failing with 503 Service Unavailable, 0 means it waits until the
+	// request times out. It represents as a duration string.
+	MaxWait string
+}
+
+// AutoEventHealthInfo is a struct which contains configuration of the handling
+// of the consecutive read failures of an AutoEvent.
+type AutoEventHealthInfo struct {
+	// InitialBackoff is the minimum delay before the next execution of an
+	// AutoEvent after a read failure, doubled with each consecutive failure.
+	// It represents as a duration string, empty disables the backoff.
+	InitialBackoff string
+	// MaxBackoff caps the exponential backoff of InitialBackoff.
+	MaxBackoff string
+	// FailureThreshold is the number of consecutive read failures after which
+	// the OperatingState of the device is set to DISABLED, 0 means never.
+	FailureThreshold int
+	// ProbeInterval is the interval at which a device disabled because of read
+	// failures is read again, the device is enabled once a read succeeds. It
+	// represents as a duration string.
+	ProbeInterval string
+}
+
+// HotReloadInfo is a struct which contains configuration of the hot reload of
+// the device profiles in ProfilesDir, of the devices in DevicesDir and of the
+// DeviceList of the local configuration file.
+type HotReloadInfo struct {
+	// Enabled controls whether the files are polled for changes.
+	Enabled bool
+	// Interval is the time between two polls of the files, which are compared
+	// by modification time and size. It represents as a duration string.
+	Interval string
+	// DeleteRemoved controls whether the profiles whose file has been removed,
+	// and the devices removed from the DeviceList, are deleted from Core
+	// Metadata. Otherwise they are only logged.
+	DeleteRemoved bool
+}
+
 // DeviceConfig is the definition of Devices which will be auto created when the Device Service starts up
 type DeviceConfig struct {
 	// Name is the Device name
@@ -135,4 +351,47 @@ type Telemetry struct {
 	Mallocs,
 	Frees,
 	LiveObjects uint64
+	// EventOutbox reports the state of the event outbox, if enabled.
+	EventOutbox *outbox.Metrics `json:",omitempty"`
+	// EventBatch reports the batches pushed to Core Data, if event batching is enabled.
+	EventBatch *batcher.Metrics `json:",omitempty"`
+	// DriverQueue reports the queueing of driver calls, if concurrency limits are enabled.
+	DriverQueue *limiter.Metrics `json:",omitempty"`
+}
+
+// LivenessInfo is a struct which contains configuration of the monitoring of
+// device reachability, which requires the driver to implement DevicePinger.
+type LivenessInfo struct {
+	// Enabled controls whether the devices are pinged periodically.
+	Enabled bool
+	// Interval is the interval between two pings of a device.
+	// It represents as a duration string.
+	Interval string
+	// Timeout is the longest time a ping may take before it is considered
+	// failed. It represents as a duration string, empty means Interval.
+	Timeout string
+	// FailureThreshold is the number of consecutive failed pings after which
+	// a device is considered unreachable.
+	FailureThreshold int
+	// UpdateOperatingState controls whether the OperatingState of unreachable
+	// devices is set to DISABLED, and back to ENABLED when they recover.
+	UpdateOperatingState bool
+}
+
+// AssertionInfo is a struct which contains configuration of the handling of
+// the readings failing the assertion of their device resource. The Policy and
+// FailureThreshold can be overridden by the ds-assertion-policy and
+// ds-assertion-failures attributes of the device resource.
+type AssertionInfo struct {
+	// Policy is Disable to disable the device, Drop to remove the reading
+	// from the event, or Mark to replace its value by a description of the
+	// failure without disabling the device. Disable also marks the readings.
+	Policy string
+	// FailureThreshold is the number of consecutive failures of the assertion
+	// of a device resource after which the Disable policy disables the device.
+	FailureThreshold int
+	// ReenableAfter is the time after which a device disabled because of an
+	// assertion failure is enabled again. It represents as a duration string,
+	// empty means never.
+	ReenableAfter string
 }
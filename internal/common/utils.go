@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
@@ -18,9 +19,12 @@ import (
 	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/batcher"
+	"github.com/edgexfoundry/device-sdk-go/internal/outbox"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 )
 
@@ -66,7 +70,27 @@ func CommandValueToReading(cv *dsModels.CommandValue, devName string, mediaType
 	return reading
 }
 
+// MarkCommandValue sets the quality of a CommandValue which failed a transform,
+// an assertion or a mapping. Unless ReadingQuality is enabled, the value is
+// replaced by the description of the failure, as the quality doesn't reach
+// Core Data.
+func MarkCommandValue(cv *dsModels.CommandValue, quality dsModels.Quality, reason string, description string) *dsModels.CommandValue {
+	if CurrentConfig != nil && CurrentConfig.Device.ReadingQuality {
+		cv.SetQuality(quality, reason)
+		return cv
+	}
+	marked := dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, description)
+	marked.SetQuality(quality, reason)
+	return marked
+}
+
+// SendEvent encodes the event and fans it out to the configured event sinks.
+// Core Data is the only sink unless others have been configured. The readings
+// of Bad quality are withheld, see publishableEvent.
 func SendEvent(event *dsModels.Event) {
+	if event = publishableEvent(event); event == nil {
+		return
+	}
 	correlation := uuid.New().String()
 	ctx := context.WithValue(context.Background(), CorrelationHeader, correlation)
 	if event.HasBinaryValue() {
@@ -86,13 +110,180 @@ func SendEvent(event *dsModels.Event) {
 	} else {
 		LoggingClient.Debug("SendEvent: EventClient.MarshalEvent passed through encoded event", clients.CorrelationHeader, correlation)
 	}
+
+	sinks := EventSinks()
+	if len(sinks) == 0 {
+		PushEvent(ctx, event)
+		return
+	}
+	for _, sink := range sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			LoggingClient.Error(fmt.Sprintf("SendEvent: %s event sink failed to send event", sink.Name()), "device", event.Device, clients.CorrelationHeader, correlation, "error", err)
+		}
+	}
+}
+
+// publishableEvent returns the event without the readings of Bad quality when
+// ReadingQuality is enabled, as their quality can't be carried by the readings
+// of Core Data, which would store them as valid values. The event is copied
+// rather than modified, as it may also be returned to a command. It returns
+// nil if no reading is left.
+func publishableEvent(event *dsModels.Event) *dsModels.Event {
+	if len(event.Qualities) == 0 || CurrentConfig == nil || !CurrentConfig.Device.ReadingQuality {
+		return event
+	}
+	readings := make([]contract.Reading, 0, len(event.Readings))
+	for _, r := range event.Readings {
+		if q, ok := event.Qualities[r.Name]; ok && q.Quality == dsModels.QualityBad {
+			LoggingClient.Warn(fmt.Sprintf("SendEvent: reading %s of device %s withheld, its quality is %s (%s)", r.Name, event.Device, q.Quality, q.Reason))
+			continue
+		}
+		readings = append(readings, r)
+	}
+	if len(readings) == len(event.Readings) {
+		return event
+	}
+	if len(readings) == 0 {
+		return nil
+	}
+	published := *event
+	published.Readings = readings
+	published.EncodedEvent = nil
+	return &published
+}
+
+// PushEvent pushes an encoded event to core data, either directly or through
+// the event batcher and outbox if they are enabled. The returned channel
+// receives the outcome of the delivery (of the whole batch when batching is
+// enabled) and may be ignored.
+func PushEvent(ctx context.Context, event *dsModels.Event) <-chan batcher.Result {
+	correlation := clients.FromContext(ctx, CorrelationHeader)
+	contentType := clients.FromContext(ctx, clients.ContentType)
+	if contentType == "" {
+		contentType = clients.ContentTypeJSON
+		if event.HasBinaryValue() {
+			contentType = clients.ContentTypeCBOR
+		}
+		ctx = context.WithValue(ctx, clients.ContentType, contentType)
+	}
+
+	if EventBatcher != nil {
+		LoggingClient.Debug("SendEvent: Adding event to the current batch", "device", event.Device, clients.CorrelationHeader, correlation)
+		return EventBatcher.Add(batcher.Event{Device: event.Device, ContentType: contentType, Payload: event.EncodedEvent, Correlation: correlation})
+	}
+
+	result := make(chan batcher.Result, 1)
+	err := deliverEvent(ctx, event.Device, contentType, event.EncodedEvent)
+	if err != nil {
+		result <- batcher.Result{Events: 1, Failed: 1, Err: err}
+	} else {
+		result <- batcher.Result{Events: 1}
+	}
+	return result
+}
+
+// FlushEventBatch delivers the events of a batch in order, as they were sent
+// and with the correlation ID of their sender. Core Data doesn't provide a
+// bulk endpoint, so each event is pushed in its own request.
+func FlushEventBatch(ctx context.Context, b batcher.Batch) []error {
+	errs := make([]error, len(b.Events))
+	for i, e := range b.Events {
+		correlation := e.Correlation
+		if correlation == "" {
+			correlation = uuid.New().String()
+		}
+		eventCtx := context.WithValue(ctx, CorrelationHeader, correlation)
+		eventCtx = context.WithValue(eventCtx, clients.ContentType, b.ContentType)
+		errs[i] = deliverEvent(eventCtx, e.Device, b.ContentType, e.Payload)
+	}
+	return errs
+}
+
+// deliverEvent writes an encoded event through the outbox if enabled,
+// otherwise it posts the event to core data.
+func deliverEvent(ctx context.Context, device string, contentType string, payload []byte) error {
+	correlation := clients.FromContext(ctx, CorrelationHeader)
+	if EventOutbox != nil {
+		record := outbox.Record{Device: device, ContentType: contentType, Payload: payload}
+		err := EventOutbox.Enqueue(record)
+		if err != nil {
+			LoggingClient.Error("SendEvent Failed to queue event", "device", device, clients.CorrelationHeader, correlation, "error", err)
+		} else {
+			LoggingClient.Debug("SendEvent: Queued event in the outbox", "device", device, clients.CorrelationHeader, correlation)
+		}
+		return err
+	}
+
 	// Call AddBytes to post event to core data
-	responseBody, errPost := EventClient.AddBytes(ctx, event.EncodedEvent)
+	responseBody, errPost := EventClient.AddBytes(ctx, payload)
 	if errPost != nil {
-		LoggingClient.Error("SendEvent Failed to push event", "device", event.Device, "response", responseBody, "error", errPost)
+		LoggingClient.Error("SendEvent Failed to push event", "device", device, "response", responseBody, "error", errPost)
 	} else {
-		LoggingClient.Info("SendEvent: Pushed event to core data", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation)
-		LoggingClient.Trace("SendEvent: Pushed this event to core data", clients.ContentType, clients.FromContext(ctx, clients.ContentType), clients.CorrelationHeader, correlation, "event", event)
+		LoggingClient.Info("SendEvent: Pushed event to core data", clients.ContentType, contentType, clients.CorrelationHeader, correlation)
+		LoggingClient.Trace("SendEvent: Pushed this event to core data", clients.ContentType, contentType, clients.CorrelationHeader, correlation, "device", device)
+	}
+	return errPost
+}
+
+// ForwardEvent pushes an event queued in the outbox to core data. Events
+// rejected by core data with a client error are reported as permanent
+// failures so that they don't block the outbox forever.
+func ForwardEvent(ctx context.Context, r outbox.Record) error {
+	correlation := uuid.New().String()
+	ctx = context.WithValue(ctx, CorrelationHeader, correlation)
+	ctx = context.WithValue(ctx, clients.ContentType, r.ContentType)
+
+	responseBody, err := EventClient.AddBytes(ctx, r.Payload)
+	if err != nil {
+		if errsc, ok := err.(types.ErrServiceClient); ok && isPermanentStatus(errsc.StatusCode) {
+			return outbox.PermanentError{Err: err}
+		}
+		LoggingClient.Debug("ForwardEvent: Failed to push queued event", "device", r.Device, "response", responseBody, clients.CorrelationHeader, correlation)
+		return err
+	}
+
+	LoggingClient.Info("ForwardEvent: Pushed queued event to core data", clients.ContentType, r.ContentType, clients.CorrelationHeader, correlation)
+	return nil
+}
+
+func isPermanentStatus(code int) bool {
+	return code >= http.StatusBadRequest && code < http.StatusInternalServerError &&
+		code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+// NewCommandContext derives the context passed to the driver for a device
+// command. It carries the correlation ID and is cancelled after Service.Timeout.
+func NewCommandContext(parent context.Context, correlationID string) (context.Context, context.CancelFunc) {
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+	ctx := context.WithValue(parent, CorrelationHeader, correlationID)
+	if CurrentConfig != nil && CurrentConfig.Service.Timeout > 0 {
+		return context.WithTimeout(ctx, time.Duration(CurrentConfig.Service.Timeout)*time.Millisecond)
+	}
+	return context.WithCancel(ctx)
+}
+
+// WaitForOperations waits, up to Device.DrainTimeout, for the commands and
+// autoevents in progress on the device to complete.
+func WaitForOperations(deviceName string) {
+	timeout := DrainTimeoutDefault
+	if CurrentConfig.Device.DrainTimeout != "" {
+		d, err := time.ParseDuration(CurrentConfig.Device.DrainTimeout)
+		if err != nil {
+			LoggingClient.Warn(fmt.Sprintf("DrainTimeout %s cannot be parsed, using %v: %v", CurrentConfig.Device.DrainTimeout, timeout, err))
+		} else {
+			timeout = d
+		}
+	}
+
+	n := InFlight.Count(deviceName)
+	if n == 0 {
+		return
+	}
+	LoggingClient.Debug(fmt.Sprintf("Waiting for %d operations in progress on device %s", n, deviceName))
+	if !InFlight.Wait(deviceName, timeout) {
+		LoggingClient.Warn(fmt.Sprintf("Timed out after %v waiting for %d operations in progress on device %s", timeout, InFlight.Count(deviceName), deviceName))
 	}
 }
 
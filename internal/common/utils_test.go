@@ -8,8 +8,20 @@
 package common
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/batcher"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 )
 
 func TestBuildAddr(t *testing.T) {
@@ -112,3 +124,82 @@ func TestFilterQueryParams(t *testing.T) {
 		}
 	}
 }
+
+// recordingEventClient records the events posted to Core Data, with their
+// correlation IDs.
+type recordingEventClient struct {
+	coredata.EventClient
+	posted       [][]byte
+	correlations []string
+}
+
+func (c *recordingEventClient) AddBytes(ctx context.Context, data []byte) (string, error) {
+	c.posted = append(c.posted, data)
+	c.correlations = append(c.correlations, clients.FromContext(ctx, CorrelationHeader))
+	return "", nil
+}
+
+func TestFlushEventBatch(t *testing.T) {
+	defer func(ec coredata.EventClient) {
+		EventClient = ec
+	}(EventClient)
+	LoggingClient = logger.NewMockClient()
+	client := &recordingEventClient{}
+	EventClient = client
+
+	encode := func(device string, origin int64, correlation string) batcher.Event {
+		payload, _ := json.Marshal(contract.Event{Device: device, Origin: origin, Readings: []contract.Reading{{Device: device, Name: "R"}}})
+		return batcher.Event{Device: device, ContentType: clients.ContentTypeJSON, Payload: payload, Correlation: correlation}
+	}
+	b := batcher.Batch{ContentType: clients.ContentTypeJSON, Events: []batcher.Event{
+		encode("D1", 1, "correlation-1"),
+		encode("D2", 2, "correlation-2"),
+		encode("D1", 3, ""),
+	}}
+
+	errs := FlushEventBatch(context.Background(), b)
+	require.Len(t, errs, 3)
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	// the events are pushed in order as they were sent
+	require.Len(t, client.posted, 3)
+	for i, e := range b.Events {
+		assert.Equal(t, string(e.Payload), string(client.posted[i]))
+	}
+	assert.Equal(t, "correlation-1", client.correlations[0])
+	assert.Equal(t, "correlation-2", client.correlations[1])
+	assert.NotEmpty(t, client.correlations[2])
+}
+
+func TestPublishableEventWithholdsBadReadings(t *testing.T) {
+	defer func(config *ConfigurationStruct) {
+		CurrentConfig = config
+	}(CurrentConfig)
+	LoggingClient = logger.NewMockClient()
+	CurrentConfig = &ConfigurationStruct{Device: DeviceInfo{ReadingQuality: true}}
+
+	event := &dsModels.Event{Event: contract.Event{Device: "Quality-Device", Readings: []contract.Reading{
+		{Name: "Temperature", Value: "20"},
+		{Name: "Humidity", Value: "4096"},
+		{Name: "Mode", Value: "7"},
+	}}, EncodedEvent: []byte("encoded")}
+	event.SetQuality("Humidity", dsModels.ReadingQuality{Quality: dsModels.QualityBad, Reason: dsModels.ReasonTransformFailed})
+	event.SetQuality("Mode", dsModels.ReadingQuality{Quality: dsModels.QualityUncertain, Reason: dsModels.ReasonMappingFailed})
+
+	published := publishableEvent(event)
+	require.NotNil(t, published)
+	require.Len(t, published.Readings, 2)
+	assert.Equal(t, "Temperature", published.Readings[0].Name)
+	assert.Equal(t, "Mode", published.Readings[1].Name)
+	assert.Nil(t, published.EncodedEvent)
+	// the event returned to the command is left untouched
+	assert.Len(t, event.Readings, 3)
+
+	bad := &dsModels.Event{Event: contract.Event{Device: "Quality-Device", Readings: []contract.Reading{{Name: "Humidity"}}}}
+	bad.SetQuality("Humidity", dsModels.ReadingQuality{Quality: dsModels.QualityBad})
+	assert.Nil(t, publishableEvent(bad))
+
+	CurrentConfig.Device.ReadingQuality = false
+	assert.Equal(t, bad, publishableEvent(bad))
+}
@@ -15,9 +15,13 @@ import (
 	"net/http"
 	"runtime"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/controller/correlation"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler"
 	"github.com/edgexfoundry/device-sdk-go/internal/handler/callback"
+	"github.com/edgexfoundry/device-sdk-go/internal/validator"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
@@ -117,10 +121,15 @@ func commandFunc(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	event, appErr := handler.CommandHandler(vars, body, req.Method, req.URL.RawQuery)
+	ctx, cancel := common.NewCommandContext(req.Context(), correlation.FromContext(req.Context()))
+	defer cancel()
+	event, appErr := handler.CommandHandler(ctx, vars, body, req.Method, req.URL.RawQuery)
 
 	if appErr != nil {
 		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+	} else if event == nil && req.Method == http.MethodGet {
+		// all the readings have been dropped by their assertion failure policy
+		w.WriteHeader(http.StatusNoContent)
 	} else if event != nil {
 		if event.HasBinaryValue() {
 			// TODO: Add conditional toggle in case caller of command does not require this response.
@@ -149,8 +158,33 @@ func commandFunc(w http.ResponseWriter, req *http.Request) {
 }
 
 func commandAllFunc(w http.ResponseWriter, req *http.Request) {
+	commandDevicesFunc(w, req, func(*dsModels.DeviceSelector) {})
+}
+
+// commandLabelFunc executes the command on the operational devices having the
+// label of the request path.
+func commandLabelFunc(w http.ResponseWriter, req *http.Request) {
+	label := mux.Vars(req)[common.LabelVar]
+	commandDevicesFunc(w, req, func(selector *dsModels.DeviceSelector) {
+		selector.Labels = append(selector.Labels, label)
+	})
+}
+
+// commandProfileFunc executes the command on the operational devices having
+// the profile of the request path.
+func commandProfileFunc(w http.ResponseWriter, req *http.Request) {
+	profile := mux.Vars(req)[common.ProfileVar]
+	commandDevicesFunc(w, req, func(selector *dsModels.DeviceSelector) {
+		selector.Profile = profile
+	})
+}
+
+// commandDevicesFunc executes the command on the operational devices selected
+// by the query parameters, once narrowed by selectPath to the devices of the
+// request path.
+func commandDevicesFunc(w http.ResponseWriter, req *http.Request, selectPath func(*dsModels.DeviceSelector)) {
 	vars := mux.Vars(req)
-	common.LoggingClient.Debug(fmt.Sprintf("execute the Get command %s from all operational devices", vars[common.CommandVar]))
+	common.LoggingClient.Debug(fmt.Sprintf("execute the %s command %s from the selected operational devices", req.Method, vars[common.CommandVar]))
 
 	if checkServiceLocked(w, req) {
 		return
@@ -161,19 +195,134 @@ func commandAllFunc(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	events, appErr := handler.CommandAllHandler(vars[common.CommandVar], body, req.Method, req.URL.RawQuery)
+	selector, err := handler.ParseDeviceSelector(req.URL.RawQuery)
+	if err != nil {
+		msg := fmt.Sprintf("invalid device selector: %v", err)
+		common.LoggingClient.Error(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	selectPath(&selector)
+
+	ctx, cancel := common.NewCommandContext(req.Context(), correlation.FromContext(req.Context()))
+	defer cancel()
+	results, appErr := handler.SelectorCommandHandler(ctx, selector, vars[common.CommandVar], body, req.Method, req.URL.RawQuery)
+	if appErr != nil && results == nil {
+		http.Error(w, appErr.Message(), appErr.Code())
+		return
+	}
+	writeMultiStatus(w, results)
+}
+
+// writeMultiStatus pushes the events read to Core Data, and writes the result
+// of every device of a command issued to several devices.
+func writeMultiStatus(w http.ResponseWriter, results []handler.DeviceResult) {
+	for _, r := range results {
+		if r.Event != nil {
+			// push to Core Data
+			go common.SendEvent(r.Event)
+		}
+	}
+	response := handler.NewMultiStatusResponse(results)
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	w.WriteHeader(response.StatusCode)
+	json.NewEncoder(w).Encode(response)
+}
+
+// validateProfileFunc validates the device profile, in YAML or JSON, posted
+// in the request body and returns the validation report. The response status
+// is 400 Bad Request when the profile is invalid.
+func validateProfileFunc(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		msg := fmt.Sprintf("error reading request body for: %s %s", req.Method, req.URL)
+		common.LoggingClient.Error(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	if len(body) == 0 {
+		msg := fmt.Sprintf("no request body provided; %s %s", req.Method, req.URL)
+		common.LoggingClient.Error(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	report := validator.ValidateBytes(body)
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	if !report.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// autoEventsFunc reports the state of the AutoEvents of the device named in
+// the route, or of all the devices.
+func autoEventsFunc(w http.ResponseWriter, req *http.Request) {
+	status, appErr := autoevent.GetManager().Status(mux.Vars(req)[common.NameVar])
 	if appErr != nil {
 		http.Error(w, appErr.Message(), appErr.Code())
-	} else if len(events) > 0 {
-		// push to Core Data
-		for _, event := range events {
-			if event != nil {
-				go common.SendEvent(event)
-			}
+		return
+	}
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	json.NewEncoder(w).Encode(status)
+}
+
+// autoEventsPauseFunc pauses the AutoEvents of the device named in the route,
+// or of all the devices.
+func autoEventsPauseFunc(w http.ResponseWriter, req *http.Request) {
+	if checkServiceLocked(w, req) {
+		return
+	}
+
+	if name, ok := mux.Vars(req)[common.NameVar]; ok {
+		if appErr := autoevent.GetManager().PauseForDevice(name); appErr != nil {
+			http.Error(w, appErr.Message(), appErr.Code())
+			return
+		}
+	} else {
+		autoevent.GetManager().PauseAutoEvents()
+	}
+	io.WriteString(w, statusOK)
+}
+
+// autoEventsResumeFunc resumes the AutoEvents of the device named in the
+// route, or all the AutoEvents.
+func autoEventsResumeFunc(w http.ResponseWriter, req *http.Request) {
+	if checkServiceLocked(w, req) {
+		return
+	}
+
+	if name, ok := mux.Vars(req)[common.NameVar]; ok {
+		if appErr := autoevent.GetManager().ResumeForDevice(name); appErr != nil {
+			http.Error(w, appErr.Message(), appErr.Code())
+			return
 		}
-		w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
-		json.NewEncoder(w).Encode(events)
+	} else {
+		autoevent.GetManager().ResumeAutoEvents()
+	}
+	io.WriteString(w, statusOK)
+}
+
+// autoEventTriggerFunc executes an AutoEvent immediately and returns the
+// event published.
+func autoEventTriggerFunc(w http.ResponseWriter, req *http.Request) {
+	if checkServiceLocked(w, req) {
+		return
+	}
+
+	vars := mux.Vars(req)
+	event, appErr := autoevent.GetManager().TriggerForDevice(req.Context(), vars[common.NameVar], vars[common.ResourceVar])
+	if appErr != nil {
+		http.Error(w, fmt.Sprintf("%s %s", appErr.Message(), req.URL.Path), appErr.Code())
+		return
+	}
+	if event == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	json.NewEncoder(w).Encode(event)
 }
 
 func checkServiceLocked(w http.ResponseWriter, req *http.Request) bool {
@@ -225,6 +374,19 @@ func metricsHandler(w http.ResponseWriter, _ *http.Request) {
 	// Live objects = Mallocs - Frees
 	t.LiveObjects = t.Mallocs - t.Frees
 
+	if common.EventOutbox != nil {
+		m := common.EventOutbox.Metrics()
+		t.EventOutbox = &m
+	}
+	if common.EventBatcher != nil {
+		m := common.EventBatcher.Metrics()
+		t.EventBatch = &m
+	}
+	if common.DriverLimiter != nil {
+		m := common.DriverLimiter.Metrics()
+		t.DriverQueue = &m
+	}
+
 	encode(t, w)
 
 	return
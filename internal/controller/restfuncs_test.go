@@ -9,17 +9,22 @@ package controller
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -126,3 +131,73 @@ func TestCommandNoDevice(t *testing.T) {
 		t.Errorf("No Device: handler returned wrong body:\nexpected: %s\ngot:      %s", expected, body)
 	}
 }
+
+// TestCommandSelectedDevices tests the command REST calls issued to the
+// devices of a label or a profile.
+func TestCommandSelectedDevices(t *testing.T) {
+	common.LoggingClient = logger.NewClient("command_test", false, "./command_test.log", "DEBUG")
+	common.ServiceLocked = false
+	common.CurrentConfig = &common.ConfigurationStruct{}
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	cache.InitCache()
+	r := mux.NewRouter()
+	controller := NewRestController(r)
+	controller.InitRestRoutes()
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"Label", clients.ApiDeviceRoute + "/label/no-such-label/" + testCmd, http.StatusOK},
+		{"Profile", clients.ApiDeviceRoute + "/profile/No-Such-Profile/" + testCmd, http.StatusOK},
+		{"InvalidNamePattern", clients.ApiDeviceRoute + "/label/no-such-label/" + testCmd + "?ds-name=%5B", http.StatusBadRequest},
+		{"InvalidProperty", clients.ApiDeviceRoute + "/profile/No-Such-Profile/" + testCmd + "?ds-property=Address", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			controller.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			require.Equal(t, tt.status, rr.Code, rr.Body.String())
+			if tt.status != http.StatusOK {
+				return
+			}
+			var response dsModels.MultiStatusResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Empty(t, response.Devices)
+		})
+	}
+}
+
+// Test the profile validation REST call
+func TestValidateProfile(t *testing.T) {
+	var tests = []struct {
+		name string
+		body string
+		code int
+	}{
+		{"Empty body", "", http.StatusBadRequest},
+		{"Valid profile", `{"name":"Test","deviceResources":[{"name":"Temperature","properties":{"value":{"type":"Float32"}}}],"deviceCommands":[{"name":"Read","get":[{"deviceResource":"Temperature"}]}]}`, http.StatusOK},
+		{"Unknown resource", `{"name":"Test","deviceResources":[{"name":"Temperature","properties":{"value":{"type":"Float32"}}}],"deviceCommands":[{"name":"Read","get":[{"deviceResource":"Humidity"}]}]}`, http.StatusBadRequest},
+		{"Malformed", "name: [", http.StatusBadRequest},
+	}
+
+	common.LoggingClient = logger.NewMockClient()
+	r := mux.NewRouter()
+	controller := NewRestController(r)
+	controller.InitRestRoutes()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, common.APIProfileValidateRoute, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			controller.router.ServeHTTP(rr, req)
+			if status := rr.Code; status != tt.code {
+				t.Errorf("ValidateProfile: handler returned wrong status code: got %v want %v", status, tt.code)
+			}
+		})
+	}
+}
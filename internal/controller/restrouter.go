@@ -50,11 +50,23 @@ func (c RestController) InitRestRoutes() {
 	c.addReservedRoute(common.APIAllCommandRoute, commandAllFunc).Methods(http.MethodGet, http.MethodPut)
 	c.addReservedRoute(common.APIIdCommandRoute, commandFunc).Methods(http.MethodGet, http.MethodPut)
 	c.addReservedRoute(common.APINameCommandRoute, commandFunc).Methods(http.MethodGet, http.MethodPut)
+	c.addReservedRoute(common.APILabelCommandRoute, commandLabelFunc).Methods(http.MethodGet, http.MethodPut)
+	c.addReservedRoute(common.APIProfileCommandRoute, commandProfileFunc).Methods(http.MethodGet, http.MethodPut)
 	// Callback
 	c.addReservedRoute(common.APICallbackRoute, callbackFunc)
 	// Discovery and Transform
 	c.addReservedRoute(common.APIDiscoveryRoute, discoveryFunc).Methods(http.MethodPost)
 	c.addReservedRoute(common.APITransformRoute, transformFunc).Methods(http.MethodGet)
+	// Profile validation
+	c.addReservedRoute(common.APIProfileValidateRoute, validateProfileFunc).Methods(http.MethodPost)
+	// AutoEvents
+	c.addReservedRoute(common.APIAutoEventsRoute, autoEventsFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APIAutoEventsPauseRoute, autoEventsPauseFunc).Methods(http.MethodPost)
+	c.addReservedRoute(common.APIAutoEventsResumeRoute, autoEventsResumeFunc).Methods(http.MethodPost)
+	c.addReservedRoute(common.APIDeviceAutoEventsRoute, autoEventsFunc).Methods(http.MethodGet)
+	c.addReservedRoute(common.APIDeviceAutoEventsPauseRoute, autoEventsPauseFunc).Methods(http.MethodPost)
+	c.addReservedRoute(common.APIDeviceAutoEventsResumeRoute, autoEventsResumeFunc).Methods(http.MethodPost)
+	c.addReservedRoute(common.APIDeviceAutoEventTriggerRoute, autoEventTriggerFunc).Methods(http.MethodPost)
 	// Metric and Config
 	c.addReservedRoute(common.APIMetricsRoute, metricsHandler).Methods(http.MethodGet)
 	c.addReservedRoute(common.APIConfigRoute, configHandler).Methods(http.MethodGet)
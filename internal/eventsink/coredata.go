@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package eventsink provides the built-in implementations of the
+// models.EventSink interface.
+package eventsink
+
+import (
+	"context"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+const (
+	CoreDataSinkName  = "CoreData"
+	FileSinkName      = "File"
+	PublisherSinkName = "Publisher"
+)
+
+type coreDataSink struct{}
+
+// NewCoreDataSink returns a sink which pushes events to Core Data through the
+// event batcher and outbox, if they are enabled.
+func NewCoreDataSink() dsModels.EventSink {
+	return coreDataSink{}
+}
+
+func (coreDataSink) Name() string {
+	return CoreDataSinkName
+}
+
+func (coreDataSink) Send(ctx context.Context, event *dsModels.Event) error {
+	result := common.PushEvent(ctx, event)
+	select {
+	case r := <-result:
+		return r.Err
+	default:
+		// the event has been batched, the outcome of the batch is logged and
+		// counted in the EventBatch metrics
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+type publisherMock struct {
+	mutex    sync.Mutex
+	topics   []string
+	payloads [][]byte
+	err      error
+}
+
+func (p *publisherMock) Publish(_ context.Context, topic string, contentType string, payload []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.topics = append(p.topics, topic)
+	p.payloads = append(p.payloads, payload)
+	return nil
+}
+
+func testEvent(device string) *dsModels.Event {
+	return &dsModels.Event{
+		Event:        contract.Event{Device: device, Readings: []contract.Reading{{Name: "temperature", Value: "21"}}},
+		EncodedEvent: []byte(fmt.Sprintf(`{"device":"%s"}`, device)),
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.jsonl")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Send(context.Background(), testEvent("device-1")))
+	require.NoError(t, sink.Send(context.Background(), testEvent("device-2")))
+	require.NoError(t, sink.Close())
+	assert.Error(t, sink.Send(context.Background(), testEvent("device-3")), "sending to a closed sink must fail")
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var devices []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e contract.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		devices = append(devices, e.Device)
+	}
+	assert.Equal(t, []string{"device-1", "device-2"}, devices)
+
+	_, err = NewFileSink("")
+	assert.Error(t, err)
+}
+
+func TestPublisherSink(t *testing.T) {
+	_, err := NewPublisherSink(nil, "events")
+	assert.Error(t, err, "a publisher is required")
+
+	publisher := &publisherMock{}
+	sink, err := NewPublisherSink(publisher, "edgex/events/{device}")
+	require.NoError(t, err)
+	require.NoError(t, sink.Send(context.Background(), testEvent("device-1")))
+
+	assert.Equal(t, []string{"edgex/events/device-1"}, publisher.topics)
+	assert.Equal(t, `{"device":"device-1"}`, string(publisher.payloads[0]))
+}
+
+func TestSendEventFansOutToAllSinks(t *testing.T) {
+	common.LoggingClient = logger.NewMockClient()
+	defer common.ClearEventSinks()
+
+	failing := &publisherMock{err: fmt.Errorf("broker unreachable")}
+	failingSink, err := NewPublisherSink(failing, "failing")
+	require.NoError(t, err)
+	publisher := &publisherMock{}
+	sink, err := NewPublisherSink(publisher, "events")
+	require.NoError(t, err)
+	common.AddEventSink(failingSink)
+	common.AddEventSink(sink)
+
+	common.SendEvent(testEvent("device-1"))
+
+	assert.Len(t, publisher.payloads, 1, "a failing sink must not prevent delivery to the other sinks")
+}
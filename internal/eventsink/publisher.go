@@ -0,0 +1,50 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// DeviceTopicPlaceholder is replaced with the device name in publisher topics.
+const DeviceTopicPlaceholder = "{device}"
+
+type publisherSink struct {
+	publisher dsModels.EventPublisher
+	topic     string
+}
+
+// NewPublisherSink returns a sink which publishes the encoded events through
+// the given message bus publisher.
+func NewPublisherSink(publisher dsModels.EventPublisher, topic string) (dsModels.EventSink, error) {
+	if publisher == nil {
+		return nil, fmt.Errorf("the %s event sink requires the device service to implement EventPublisher", PublisherSinkName)
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("no topic specified for the %s event sink", PublisherSinkName)
+	}
+	return publisherSink{publisher: publisher, topic: topic}, nil
+}
+
+func (s publisherSink) Name() string {
+	return PublisherSinkName
+}
+
+func (s publisherSink) Send(ctx context.Context, event *dsModels.Event) error {
+	contentType := clients.ContentTypeJSON
+	if event.HasBinaryValue() {
+		contentType = clients.ContentTypeCBOR
+	}
+	topic := strings.Replace(s.topic, DeviceTopicPlaceholder, event.Device, -1)
+	return s.publisher.Publish(ctx, topic, contentType, event.EncodedEvent)
+}
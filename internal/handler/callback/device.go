@@ -95,12 +95,17 @@ func handleUpdateDevice(ctx context.Context, id string) common.AppError {
 	err = cache.Devices().Update(device)
 	if err == nil {
 		common.LoggingClient.Info(fmt.Sprintf("Updated device: %s", device.Name))
+		if device.OperatingState == contract.Enabled {
+			// a device enabled by an operator is no longer disabled by the SDK
+			common.ClearDisabledBy(device.Name)
+		}
 	} else {
 		appErr := common.NewServerError(err.Error(), err)
 		common.LoggingClient.Error(fmt.Sprintf("Couldn't update device %s: %v", device.Name, err.Error()))
 		return appErr
 	}
 
+	common.WaitForOperations(device.Name)
 	err = common.Driver.UpdateDevice(device.Name, device.Protocols, device.AdminState)
 	if err == nil {
 		common.LoggingClient.Debug(fmt.Sprintf("Invoked driver.UpdateDevice callback for %s", device.Name))
@@ -126,12 +131,14 @@ func handleDeleteDevice(id string) common.AppError {
 	err := cache.Devices().Remove(id)
 	if err == nil {
 		common.LoggingClient.Info(fmt.Sprintf("Removed device: %s", device.Name))
+		common.ClearDisabledBy(device.Name)
 	} else {
 		appErr := common.NewServerError(err.Error(), err)
 		common.LoggingClient.Error(fmt.Sprintf("Couldn't remove device %s: %v", device.Name, err.Error()))
 		return appErr
 	}
 
+	common.WaitForOperations(device.Name)
 	err = common.Driver.RemoveDevice(device.Name, device.Protocols)
 	if err == nil {
 		common.LoggingClient.Debug(fmt.Sprintf("Invoked driver.RemoveDevice callback for %s", device.Name))
@@ -0,0 +1,65 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// MaxCommandChainDepth is the maximum nesting of deviceCommands referencing
+// other deviceCommands in their resource operations.
+const MaxCommandChainDepth = 8
+
+// expandResourceOperations resolves resource command chaining: a resource
+// operation may reference another deviceCommand of the profile instead of a
+// deviceResource, in which case it is replaced by the resource operations of
+// that command, recursively. A name matching a deviceResource always refers
+// to the deviceResource. The flattened set is bounded by Device.MaxCmdOps.
+func expandResourceOperations(profileName string, cmd string, method string, ros []contract.ResourceOperation) ([]contract.ResourceOperation, error) {
+	result := make([]contract.ResourceOperation, 0, len(ros))
+	err := expandInto(&result, profileName, method, ros, []string{cmd})
+	return result, err
+}
+
+func expandInto(result *[]contract.ResourceOperation, profileName string, method string, ros []contract.ResourceOperation, chain []string) error {
+	for _, ro := range ros {
+		if _, ok := cache.Profiles().DeviceResource(profileName, ro.DeviceResource); ok {
+			*result = append(*result, ro)
+			if len(*result) > common.CurrentConfig.Device.MaxCmdOps {
+				return fmt.Errorf("MaxCmdOps (%d) exceeded by command chain %s", common.CurrentConfig.Device.MaxCmdOps, strings.Join(chain, " -> "))
+			}
+			continue
+		}
+
+		nested, err := cache.Profiles().ResourceOperations(profileName, ro.DeviceResource, method)
+		if err != nil {
+			return fmt.Errorf("resource operation %s of command %s references neither a deviceResource nor a deviceCommand in profile %s",
+				ro.DeviceResource, chain[len(chain)-1], profileName)
+		}
+		for _, c := range chain {
+			if c == ro.DeviceResource {
+				return fmt.Errorf("command chain cycle detected in profile %s: %s -> %s", profileName, strings.Join(chain, " -> "), ro.DeviceResource)
+			}
+		}
+		if len(chain) >= MaxCommandChainDepth {
+			return fmt.Errorf("command chain %s -> %s exceeds the maximum depth of %d in profile %s",
+				strings.Join(chain, " -> "), ro.DeviceResource, MaxCommandChainDepth, profileName)
+		}
+
+		common.LoggingClient.Debug(fmt.Sprintf("Handler - expanding command %s chained from %s", ro.DeviceResource, chain[len(chain)-1]))
+		if err := expandInto(result, profileName, method, nested, append(chain[:len(chain):len(chain)], ro.DeviceResource)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"fmt"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+const chainProfile = "Chain-Profile"
+
+func getOps(names ...string) []contract.ResourceOperation {
+	ros := make([]contract.ResourceOperation, len(names))
+	for i, name := range names {
+		ros[i] = contract.ResourceOperation{DeviceResource: name}
+	}
+	return ros
+}
+
+func addChainProfile(t *testing.T) {
+	profile := contract.DeviceProfile{
+		Name: chainProfile,
+		DeviceResources: []contract.DeviceResource{
+			{Name: "Temperature"}, {Name: "Humidity"}, {Name: "Pressure"},
+		},
+		DeviceCommands: []contract.ProfileResource{
+			{Name: "Climate", Get: getOps("Temperature", "Humidity")},
+			{Name: "Weather", Get: getOps("Climate", "Pressure")},
+			{Name: "Loop1", Get: getOps("Loop2")},
+			{Name: "Loop2", Get: getOps("Loop1")},
+			{Name: "Broken", Get: getOps("Missing")},
+		},
+	}
+	for i := 0; i <= MaxCommandChainDepth; i++ {
+		profile.DeviceCommands = append(profile.DeviceCommands,
+			contract.ProfileResource{Name: fmt.Sprintf("Deep%d", i), Get: getOps(fmt.Sprintf("Deep%d", i+1))})
+	}
+	profile.DeviceCommands = append(profile.DeviceCommands,
+		contract.ProfileResource{Name: fmt.Sprintf("Deep%d", MaxCommandChainDepth+1), Get: getOps("Pressure")})
+
+	_ = cache.Profiles().RemoveByName(chainProfile)
+	require.NoError(t, cache.Profiles().Add(profile))
+}
+
+func TestExpandResourceOperations(t *testing.T) {
+	addChainProfile(t)
+	defer cache.Profiles().RemoveByName(chainProfile)
+
+	tests := []struct {
+		name      string
+		cmd       string
+		expected  []string
+		expectErr bool
+	}{
+		{"NoChaining", "Climate", []string{"Temperature", "Humidity"}, false},
+		{"Nested", "Weather", []string{"Temperature", "Humidity", "Pressure"}, false},
+		{"Cycle", "Loop1", nil, true},
+		{"UnknownReference", "Broken", nil, true},
+		{"TooDeep", "Deep0", nil, true},
+		{"WithinDepth", fmt.Sprintf("Deep%d", 2), []string{"Pressure"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ros, err := cache.Profiles().ResourceOperations(chainProfile, tt.cmd, common.GetCmdMethod)
+			require.NoError(t, err)
+
+			expanded, err := expandResourceOperations(chainProfile, tt.cmd, common.GetCmdMethod, ros)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, ro := range expanded {
+				names = append(names, ro.DeviceResource)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestExpandResourceOperationsMaxCmdOps(t *testing.T) {
+	addChainProfile(t)
+	defer cache.Profiles().RemoveByName(chainProfile)
+
+	maxCmdOps := common.CurrentConfig.Device.MaxCmdOps
+	common.CurrentConfig.Device.MaxCmdOps = 2
+	defer func() { common.CurrentConfig.Device.MaxCmdOps = maxCmdOps }()
+
+	ros, err := cache.Profiles().ResourceOperations(chainProfile, "Weather", common.GetCmdMethod)
+	require.NoError(t, err)
+	_, err = expandResourceOperations(chainProfile, "Weather", common.GetCmdMethod, ros)
+	assert.Error(t, err, "MaxCmdOps must apply to the flattened resource operations")
+}
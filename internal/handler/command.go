@@ -9,6 +9,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
@@ -16,7 +17,6 @@ import (
 	"math"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
@@ -28,7 +28,18 @@ import (
 
 // Note, every HTTP request to ServeHTTP is made in a separate goroutine, which
 // means care needs to be taken with respect to shared data accessed through *Server.
-func CommandHandler(vars map[string]string, body string, method string, queryParams string) (*dsModels.Event, common.AppError) {
+func CommandHandler(ctx context.Context, vars map[string]string, body string, method string, queryParams string) (*dsModels.Event, common.AppError) {
+	return commandHandler(ctx, vars, body, method, queryParams, false)
+}
+
+// ProbeHandler executes a Get command on a device even if its OperatingState
+// is disabled, to check whether the device has recovered.
+func ProbeHandler(ctx context.Context, deviceName string, cmd string) (*dsModels.Event, common.AppError) {
+	vars := map[string]string{common.NameVar: deviceName, common.CommandVar: cmd}
+	return commandHandler(ctx, vars, "", common.GetCmdMethod, "", true)
+}
+
+func commandHandler(ctx context.Context, vars map[string]string, body string, method string, queryParams string, probe bool) (*dsModels.Event, common.AppError) {
 	dKey := vars[common.IdVar]
 	cmd := vars[common.CommandVar]
 
@@ -52,13 +63,21 @@ func CommandHandler(vars map[string]string, body string, method string, queryPar
 		return nil, common.NewLockedError(msg, nil)
 	}
 
-	if d.OperatingState == contract.Disabled {
+	if d.OperatingState == contract.Disabled && !probe {
 		msg := fmt.Sprintf("%s is disabled; %s", d.Name, method)
 		common.LoggingClient.Error(msg)
 		return nil, common.NewLockedError(msg, nil)
 	}
 
-	// TODO: need to mark device when operation in progress, so it can't be removed till completed
+	// mark the device while the operation is in progress, so that it isn't
+	// removed or updated in the driver until completed
+	common.InFlight.Begin(d.Name)
+	defer common.InFlight.End(d.Name)
+	if _, ok := cache.Devices().ForName(d.Name); !ok {
+		msg := fmt.Sprintf("Device: %s has been removed; %s", d.Name, method)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewNotFoundError(msg, nil)
+	}
 
 	cmdExists, err := cache.Profiles().CommandExists(d.Profile.Name, cmd, method)
 
@@ -80,15 +99,19 @@ func CommandHandler(vars map[string]string, body string, method string, queryPar
 		}
 
 		if strings.ToLower(method) == common.GetCmdMethod {
-			evt, appErr = execReadDeviceResource(&d, &dr, queryParams)
+			evt, appErr = execReadDeviceResource(ctx, &d, &dr, queryParams)
 		} else {
-			appErr = execWriteDeviceResource(&d, &dr, body)
+			appErr = execWriteDeviceResource(ctx, &d, &dr, body)
 		}
 	} else {
 		if strings.ToLower(method) == common.GetCmdMethod {
-			evt, appErr = execReadCmd(&d, cmd, queryParams)
+			evt, appErr = execReadCmd(ctx, &d, cmd, queryParams)
+		} else if mode, err := parseWriteMode(queryParams); err != nil {
+			msg := fmt.Sprintf("Handler - invalid write mode for Device: %s cmd: %s, %v", d.Name, cmd, err)
+			common.LoggingClient.Error(msg)
+			appErr = common.NewBadRequestError(msg, err)
 		} else {
-			appErr = execWriteCmd(&d, cmd, body)
+			appErr = execWriteCmd(ctx, &d, cmd, body, mode)
 		}
 	}
 
@@ -96,7 +119,7 @@ func CommandHandler(vars map[string]string, body string, method string, queryPar
 	return evt, appErr
 }
 
-func execReadDeviceResource(device *contract.Device, dr *contract.DeviceResource, queryParams string) (*dsModels.Event, common.AppError) {
+func execReadDeviceResource(ctx context.Context, device *contract.Device, dr *contract.DeviceResource, queryParams string) (*dsModels.Event, common.AppError) {
 	var reqs []dsModels.CommandRequest
 	var req dsModels.CommandRequest
 	common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: deviceResource: %s", dr.Name))
@@ -113,17 +136,21 @@ func execReadDeviceResource(device *contract.Device, dr *contract.DeviceResource
 	req.Type = dsModels.ParseValueType(dr.Properties.Value.Type)
 	reqs = append(reqs, req)
 
-	results, err := common.Driver.HandleReadCommands(device.Name, device.Protocols, reqs)
+	results, err := handleReadCommands(ctx, device, reqs)
 	if err != nil {
 		msg := fmt.Sprintf("Handler - execReadCmd: error for Device: %s DeviceResource: %s, %v", device.Name, dr.Name, err)
-		return nil, common.NewServerError(msg, err)
+		return nil, driverError(msg, err)
 	}
 
 	return cvsToEvent(device, results, dr.Name)
 }
 
+// cvsToEvent transforms the values read into the readings of an event. The
+// event is nil when all the readings have been dropped by the Drop assertion
+// failure policy.
 func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd string) (*dsModels.Event, common.AppError) {
 	readings := make([]contract.Reading, 0, common.CurrentConfig.Device.MaxCmdOps)
+	qualities := make(map[string]dsModels.ReadingQuality)
 	var transformsOK = true
 	var err error
 
@@ -138,16 +165,27 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 
 		if common.CurrentConfig.Device.DataTransform {
 			err = transformer.TransformReadResult(cv, dr.Properties.Value)
+			if err == nil {
+				err = transformer.TransformReadExpression(cv, dr)
+			}
 			if err != nil {
 				common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: CommandValue (%s) transformed failed: %v", cv.String(), err))
-				transformsOK = false
+				if common.CurrentConfig.Device.ReadingQuality {
+					cv.SetQuality(dsModels.QualityBad, dsModels.ReasonTransformFailed)
+				} else {
+					transformsOK = false
+				}
 			}
 		}
 
-		err = transformer.CheckAssertion(cv, dr.Properties.Value.Assertion, device)
+		drop, err := transformer.CheckAssertion(cv, dr, device)
 		if err != nil {
 			common.LoggingClient.Error(fmt.Sprintf("Handler - execReadCmd: Assertion failed for device resource: %s, with value: %v", cv.String(), err))
-			cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
+			if drop {
+				continue
+			}
+			cv = common.MarkCommandValue(cv, dsModels.QualityBad, dsModels.ReasonAssertionFailed,
+				fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
 		}
 
 		ro, err := cache.Profiles().ResourceOperation(device.Profile.Name, cv.DeviceResourceName, common.GetCmdMethod)
@@ -159,7 +197,9 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 				cv = newCV
 			} else {
 				common.LoggingClient.Warn(fmt.Sprintf("Handler - execReadCmd: Resource Operation (%s) mapping value (%s) failed with the mapping table: %v", ro.DeviceCommand, cv.String(), ro.Mappings))
-				//transformsOK = false  // issue #89 will discuss how to handle there is no mapping matched
+				if common.CurrentConfig.Device.ReadingQuality {
+					cv.SetQuality(dsModels.QualityUncertain, dsModels.ReasonMappingFailed)
+				}
 			}
 		}
 
@@ -172,6 +212,9 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 
 		reading := common.CommandValueToReading(cv, device.Name, dr.Properties.Value.MediaType, dr.Properties.Value.FloatEncoding)
 		readings = append(readings, *reading)
+		if !cv.IsGood() {
+			qualities[reading.Name] = dsModels.ReadingQuality{Quality: cv.Quality, Reason: cv.QualityReason}
+		}
 
 		if cv.Type == dsModels.Binary {
 			common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: device: %s DeviceResource: %v reading: binary value", device.Name, cv.DeviceResourceName))
@@ -187,10 +230,18 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 		return nil, common.NewServerError(msg, nil)
 	}
 
+	if len(cvs) > 0 && len(readings) == 0 {
+		common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: all the readings of device: %s cmd: %s have been dropped", device.Name, cmd))
+		return nil, nil
+	}
+
 	// push to Core Data
 	cevent := contract.Event{Device: device.Name, Readings: readings}
 	event := &dsModels.Event{Event: cevent}
 	event.Origin = common.GetUniqueOrigin()
+	for name, quality := range qualities {
+		event.SetQuality(name, quality)
+	}
 
 	// TODO: enforce config.MaxCmdValueLen; need to include overhead for
 	// the rest of the reading JSON + Event JSON length?  Should there be
@@ -199,7 +250,23 @@ func cvsToEvent(device *contract.Device, cvs []*dsModels.CommandValue, cmd strin
 	return event, nil
 }
 
-func execReadCmd(device *contract.Device, cmd string, queryParams string) (*dsModels.Event, common.AppError) {
+func execReadCmd(ctx context.Context, device *contract.Device, cmd string, queryParams string) (*dsModels.Event, common.AppError) {
+	reqs, appErr := readRequests(device, cmd, queryParams)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	results, err := handleReadCommands(ctx, device, reqs)
+	if err != nil {
+		msg := fmt.Sprintf("Handler - execReadCmd: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
+		return nil, driverError(msg, err)
+	}
+
+	return cvsToEvent(device, results, cmd)
+}
+
+// readRequests builds the driver requests of a read command.
+func readRequests(device *contract.Device, cmd string, queryParams string) ([]dsModels.CommandRequest, common.AppError) {
 	// make ResourceOperations
 	ros, err := cache.Profiles().ResourceOperations(device.Profile.Name, cmd, common.GetCmdMethod)
 	if err != nil {
@@ -207,6 +274,13 @@ func execReadCmd(device *contract.Device, cmd string, queryParams string) (*dsMo
 		return nil, common.NewNotFoundError(err.Error(), err)
 	}
 
+	ros, err = expandResourceOperations(device.Profile.Name, cmd, common.GetCmdMethod, ros)
+	if err != nil {
+		msg := fmt.Sprintf("Handler - execReadCmd: invalid command chaining for dev: %s cmd: %s method: GET, %v", device.Name, cmd, err)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewServerError(msg, err)
+	}
+
 	if len(ros) > common.CurrentConfig.Device.MaxCmdOps {
 		msg := fmt.Sprintf("Handler - execReadCmd: MaxCmdOps (%d) execeeded for dev: %s cmd: %s method: GET",
 			common.CurrentConfig.Device.MaxCmdOps, device.Name, cmd)
@@ -220,10 +294,6 @@ func execReadCmd(device *contract.Device, cmd string, queryParams string) (*dsMo
 		drName := op.DeviceResource
 		common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: deviceResource: %s", drName))
 
-		// TODO: add recursive support for resource command chaining. This occurs when a
-		// deviceprofile resource command operation references another resource command
-		// instead of a device resource (see BoschXDK for reference).
-
 		dr, ok := cache.Profiles().DeviceResource(device.Profile.Name, drName)
 		common.LoggingClient.Debug(fmt.Sprintf("Handler - execReadCmd: deviceResource: %v", dr))
 		if !ok {
@@ -244,16 +314,10 @@ func execReadCmd(device *contract.Device, cmd string, queryParams string) (*dsMo
 		reqs[i].Type = dsModels.ParseValueType(dr.Properties.Value.Type)
 	}
 
-	results, err := common.Driver.HandleReadCommands(device.Name, device.Protocols, reqs)
-	if err != nil {
-		msg := fmt.Sprintf("Handler - execReadCmd: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
-		return nil, common.NewServerError(msg, err)
-	}
-
-	return cvsToEvent(device, results, cmd)
+	return reqs, nil
 }
 
-func execWriteDeviceResource(device *contract.Device, dr *contract.DeviceResource, params string) common.AppError {
+func execWriteDeviceResource(ctx context.Context, device *contract.Device, dr *contract.DeviceResource, params string) common.AppError {
 	paramMap, err := parseParams(params)
 	if err != nil {
 		msg := fmt.Sprintf("Handler - execWriteDeviceResource: Put parameters parsing failed: %s", params)
@@ -283,8 +347,22 @@ func execWriteDeviceResource(device *contract.Device, dr *contract.DeviceResourc
 	reqs[0].Attributes = dr.Attributes
 	reqs[0].Type = cv.Type
 
+	var checks []writeCheck
+	if verifiedCommand(*dr, dr.Name) {
+		check, err := newWriteCheck(*dr, reqs[0], cv)
+		if err != nil {
+			msg := fmt.Sprintf("Handler - execWriteDeviceResource: %v", err)
+			common.LoggingClient.Error(msg)
+			return common.NewServerError(msg, err)
+		}
+		checks = append(checks, check)
+	}
+
 	if common.CurrentConfig.Device.DataTransform {
-		err = transformer.TransformWriteParameter(cv, dr.Properties.Value)
+		err = transformer.TransformWriteExpression(cv, *dr)
+		if err == nil {
+			err = transformer.TransformWriteParameter(cv, dr.Properties.Value)
+		}
 		if err != nil {
 			msg := fmt.Sprintf("Handler - execWriteDeviceResource: CommandValue (%s) transformed failed: %v", cv.String(), err)
 			common.LoggingClient.Error(msg)
@@ -292,75 +370,122 @@ func execWriteDeviceResource(device *contract.Device, dr *contract.DeviceResourc
 		}
 	}
 
-	err = common.Driver.HandleWriteCommands(device.Name, device.Protocols, reqs, []*dsModels.CommandValue{cv})
+	err = handleWriteCommands(ctx, device, reqs, []*dsModels.CommandValue{cv})
+	if err == nil && len(checks) > 0 {
+		err = verifyWrites(ctx, device, checks)
+	}
 	if err != nil {
 		msg := fmt.Sprintf("Handler - execWriteDeviceResource: error for Device: %s Device Resource: %s, %v", device.Name, dr.Name, err)
-		return common.NewServerError(msg, err)
+		return driverError(msg, err)
 	}
 
 	return nil
 }
 
-func execWriteCmd(device *contract.Device, cmd string, params string) common.AppError {
+func execWriteCmd(ctx context.Context, device *contract.Device, cmd string, params string, mode string) common.AppError {
+	reqs, cvs, checks, appErr := writeRequests(device, cmd, params)
+	if appErr != nil {
+		return appErr
+	}
+	return writeDeviceCommands(ctx, device, cmd, reqs, cvs, checks, mode)
+}
+
+// writeDeviceCommands passes the write requests of a command to the driver in
+// the given write mode, then reads back the parameters which are verified. In
+// the transactional write mode, the previous values are restored when the
+// verification fails.
+func writeDeviceCommands(ctx context.Context, device *contract.Device, cmd string, reqs []dsModels.CommandRequest, cvs []*dsModels.CommandValue, checks []writeCheck, mode string) common.AppError {
+	var err error
+	if mode == common.WriteModeTransactional {
+		err = handleTransactionalWriteCommands(ctx, device, reqs, cvs, checks)
+	} else {
+		err = handleWriteCommands(ctx, device, reqs, cvs)
+		if err == nil && len(checks) > 0 {
+			err = verifyWrites(ctx, device, checks)
+		}
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Handler - execWriteCmd: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
+		return driverError(msg, err)
+	}
+
+	return nil
+}
+
+// writeRequests builds the driver requests of a write command, their
+// transformed parameters and the verifications of the parameters enabled by
+// the ds-verify attribute of their device resource.
+func writeRequests(device *contract.Device, cmd string, params string) ([]dsModels.CommandRequest, []*dsModels.CommandValue, []writeCheck, common.AppError) {
 	ros, err := cache.Profiles().ResourceOperations(device.Profile.Name, cmd, common.SetCmdMethod)
 	if err != nil {
 		msg := fmt.Sprintf("Handler - execWriteCmd: can't find ResrouceOperations in Profile(%s) and Command(%s), %v", device.Profile.Name, cmd, err)
 		common.LoggingClient.Error(msg)
-		return common.NewBadRequestError(msg, err)
+		return nil, nil, nil, common.NewBadRequestError(msg, err)
+	}
+
+	ros, err = expandResourceOperations(device.Profile.Name, cmd, common.SetCmdMethod, ros)
+	if err != nil {
+		msg := fmt.Sprintf("Handler - execWriteCmd: invalid command chaining for dev: %s cmd: %s method: PUT, %v", device.Name, cmd, err)
+		common.LoggingClient.Error(msg)
+		return nil, nil, nil, common.NewServerError(msg, err)
 	}
 
 	if len(ros) > common.CurrentConfig.Device.MaxCmdOps {
 		msg := fmt.Sprintf("Handler - execWriteCmd: MaxCmdOps (%d) execeeded for dev: %s cmd: %s method: PUT",
 			common.CurrentConfig.Device.MaxCmdOps, device.Name, cmd)
 		common.LoggingClient.Error(msg)
-		return common.NewServerError(msg, nil)
+		return nil, nil, nil, common.NewServerError(msg, nil)
 	}
 
 	cvs, err := parseWriteParams(device.Profile.Name, ros, params)
 	if err != nil {
 		msg := fmt.Sprintf("Handler - execWriteCmd: Put parameters parsing failed: %s", params)
 		common.LoggingClient.Error(msg)
-		return common.NewBadRequestError(msg, err)
+		return nil, nil, nil, common.NewBadRequestError(msg, err)
 	}
 
 	reqs := make([]dsModels.CommandRequest, len(cvs))
+	var checks []writeCheck
 	for i, cv := range cvs {
 		drName := cv.DeviceResourceName
 		common.LoggingClient.Debug(fmt.Sprintf("Handler - execWriteCmd: putting deviceResource: %s", drName))
 
-		// TODO: add recursive support for resource command chaining. This occurs when a
-		// deviceprofile resource command operation references another resource command
-		// instead of a device resource (see BoschXDK for reference).
-
 		dr, ok := cache.Profiles().DeviceResource(device.Profile.Name, drName)
 		common.LoggingClient.Debug(fmt.Sprintf("Handler - execWriteCmd: putting deviceResource: %s", drName))
 		if !ok {
 			msg := fmt.Sprintf("Handler - execWriteCmd: no deviceResource: %s for dev: %s cmd: %s method: GET", drName, device.Name, cmd)
 			common.LoggingClient.Error(msg)
-			return common.NewServerError(msg, nil)
+			return nil, nil, nil, common.NewServerError(msg, nil)
 		}
 
 		reqs[i].DeviceResourceName = cv.DeviceResourceName
 		reqs[i].Attributes = dr.Attributes
 		reqs[i].Type = cv.Type
 
+		if verifiedCommand(dr, cmd) {
+			check, err := newWriteCheck(dr, reqs[i], cv)
+			if err != nil {
+				msg := fmt.Sprintf("Handler - execWriteCmd: %v", err)
+				common.LoggingClient.Error(msg)
+				return nil, nil, nil, common.NewServerError(msg, err)
+			}
+			checks = append(checks, check)
+		}
+
 		if common.CurrentConfig.Device.DataTransform {
-			err = transformer.TransformWriteParameter(cv, dr.Properties.Value)
+			err = transformer.TransformWriteExpression(cv, dr)
+			if err == nil {
+				err = transformer.TransformWriteParameter(cv, dr.Properties.Value)
+			}
 			if err != nil {
 				msg := fmt.Sprintf("Handler - execWriteCmd: CommandValue (%s) transformed failed: %v", cv.String(), err)
 				common.LoggingClient.Error(msg)
-				return common.NewServerError(msg, err)
+				return nil, nil, nil, common.NewServerError(msg, err)
 			}
 		}
 	}
 
-	err = common.Driver.HandleWriteCommands(device.Name, device.Protocols, reqs, cvs)
-	if err != nil {
-		msg := fmt.Sprintf("Handler - execWriteCmd: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
-		return common.NewServerError(msg, err)
-	}
-
-	return nil
+	return reqs, cvs, checks, nil
 }
 
 func parseWriteParams(profileName string, ros []contract.ResourceOperation, params string) ([]*dsModels.CommandValue, error) {
@@ -665,59 +790,6 @@ func float32FromBytes(numericValue []byte) (res float32, err error) {
 	return
 }
 
-func CommandAllHandler(cmd string, body string, method string, queryParams string) ([]*dsModels.Event, common.AppError) {
-	common.LoggingClient.Debug(fmt.Sprintf("Handler - CommandAll: execute the %s command %s from all operational devices", method, cmd))
-	devices := filterOperationalDevices(cache.Devices().All())
-
-	devCount := len(devices)
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(devCount)
-	cmdResults := make(chan struct {
-		event  *dsModels.Event
-		appErr common.AppError
-	}, devCount)
-
-	for i, _ := range devices {
-		go func(device *contract.Device) {
-			defer waitGroup.Done()
-			var event *dsModels.Event = nil
-			var appErr common.AppError = nil
-			if strings.ToLower(method) == common.GetCmdMethod {
-				event, appErr = execReadCmd(device, cmd, queryParams)
-			} else {
-				appErr = execWriteCmd(device, cmd, body)
-			}
-			cmdResults <- struct {
-				event  *dsModels.Event
-				appErr common.AppError
-			}{event, appErr}
-		}(devices[i])
-	}
-	waitGroup.Wait()
-	close(cmdResults)
-
-	errCount := 0
-	getResults := make([]*dsModels.Event, 0, devCount)
-	var appErr common.AppError
-	for r := range cmdResults {
-		if r.appErr != nil {
-			errCount++
-			common.LoggingClient.Error("Handler - CommandAll: " + r.appErr.Message())
-			appErr = r.appErr // only the last error will be returned
-		} else if r.event != nil {
-			getResults = append(getResults, r.event)
-		}
-	}
-
-	if errCount < devCount {
-		common.LoggingClient.Info("Handler - CommandAll: part of commands executed successfully, returning 200 OK")
-		appErr = nil
-	}
-
-	return getResults, appErr
-
-}
-
 func filterOperationalDevices(devices []contract.Device) []*contract.Device {
 	result := make([]*contract.Device, 0, len(devices))
 	for i, d := range devices {
@@ -311,7 +311,7 @@ func TestExecReadCmd(t *testing.T) {
 					common.CurrentConfig.Device.MaxCmdOps = 128
 				}()
 			}
-			v, err := execReadCmd(tt.device, tt.cmd, tt.queryParams)
+			v, err := execReadCmd(context.Background(), tt.device, tt.cmd, tt.queryParams)
 			if !tt.expectErr && err != nil {
 				t.Errorf("%s expectErr:%v error:%v", tt.testName, tt.expectErr, err)
 				return
@@ -334,6 +334,10 @@ func TestExecReadCmd(t *testing.T) {
 			if tt.testName == "ValueMappingFail" && v.Readings[0].Value != strconv.Itoa(int(mock.Int8Value)) {
 				t.Errorf("%s expect data mapping failed", tt.testName)
 			}
+			// the qualities are only reported when ReadingQuality is enabled
+			if tt.testName == "ValueMappingFail" && len(v.Qualities) != 0 {
+				t.Errorf("%s expect no reading quality, got %v", tt.testName, v.Qualities)
+			}
 		})
 	}
 }
@@ -368,37 +372,7 @@ func TestExecWriteCmd(t *testing.T) {
 					common.CurrentConfig.Device.MaxCmdOps = 128
 				}()
 			}
-			appErr := execWriteCmd(tt.device, tt.cmd, tt.params)
-			if !tt.expectErr && appErr != nil {
-				t.Errorf("%s expectErr:%v error:%v", tt.testName, tt.expectErr, appErr.Error())
-				return
-			}
-			if tt.expectErr && appErr == nil {
-				t.Errorf("%s expectErr:%v no error thrown", tt.testName, tt.expectErr)
-				return
-			}
-		})
-	}
-}
-
-func TestCommandAllHandler(t *testing.T) {
-	tests := []struct {
-		testName    string
-		cmd         string
-		body        string
-		queryParams string
-		method      string
-		expectErr   bool
-	}{
-		{"PartOfReadCommandExecutionSuccess", "RandomValue_Uint8", "", "", methodGet, false},
-		{"PartOfReadCommandExecutionSuccessWithQueryParams", "RandomValue_Uint8", "", "test=test&test2=test2", methodGet, false},
-		{"PartOfReadCommandExecutionFail", "error", "", "", methodGet, true},
-		{"PartOfWriteCommandExecutionSuccess", "RandomValue_Uint8", `{"RandomValue_Uint8":"123"}`, "", methodSet, false},
-		{"PartOfWriteCommandExecutionFail", "error", `{"RandomValue_Uint8":"123"}`, "", methodSet, true},
-	}
-	for _, tt := range tests {
-		t.Run(tt.testName, func(t *testing.T) {
-			_, appErr := CommandAllHandler(tt.cmd, tt.body, tt.method, tt.queryParams)
+			appErr := execWriteCmd(context.Background(), tt.device, tt.cmd, tt.params, common.WriteModeDefault)
 			if !tt.expectErr && appErr != nil {
 				t.Errorf("%s expectErr:%v error:%v", tt.testName, tt.expectErr, appErr.Error())
 				return
@@ -451,7 +425,7 @@ func TestCommandHandler(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.testName, func(t *testing.T) {
-			_, appErr := CommandHandler(tt.vars, tt.body, tt.method, tt.queryParams)
+			_, appErr := CommandHandler(context.Background(), tt.vars, tt.body, tt.method, tt.queryParams)
 			if !tt.expectErr && appErr != nil {
 				t.Errorf("%s expectErr:%v error:%v", tt.testName, tt.expectErr, appErr.Error())
 				return
@@ -463,3 +437,33 @@ func TestCommandHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestExecReadCmdReadingQuality(t *testing.T) {
+	common.CurrentConfig.Device.ReadingQuality = true
+	defer func() {
+		common.CurrentConfig.Device.ReadingQuality = false
+	}()
+
+	tests := []struct {
+		testName string
+		cmd      string
+		quality  dsModels.ReadingQuality
+	}{
+		{"ValueAssertionPass", "ResourceTestAssertion_Pass", dsModels.ReadingQuality{}},
+		{"ValueTransformFail", "ResourceTestTransform_Fail", dsModels.ReadingQuality{Quality: dsModels.QualityBad, Reason: dsModels.ReasonTransformFailed}},
+		{"ValueAssertionFail", "ResourceTestAssertion_Fail", dsModels.ReadingQuality{Quality: dsModels.QualityBad, Reason: dsModels.ReasonAssertionFailed}},
+		{"ValueMappingFail", "ResourceTestMapping_Fail", dsModels.ReadingQuality{Quality: dsModels.QualityUncertain, Reason: dsModels.ReasonMappingFailed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			device := deviceIntegerGenerator
+			v, err := execReadCmd(context.Background(), &device, tt.cmd, "")
+			if !assert.Nil(t, err) {
+				return
+			}
+			// the values aren't replaced by the description of the failure
+			assert.NotContains(t, v.Readings[0].Value, "failed")
+			assert.Equal(t, tt.quality, v.Qualities[v.Readings[0].Name])
+		})
+	}
+}
@@ -0,0 +1,373 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// defaultCommandAllWorkers is the number of devices, or batches of devices,
+// commanded concurrently when Device.CommandAll MaxWorkers isn't set.
+const defaultCommandAllWorkers = 16
+
+// DeviceResult is the result of a command issued to one of several devices,
+// Event being nil for write commands.
+type DeviceResult struct {
+	DeviceName string
+	Event      *dsModels.Event
+	Err        common.AppError
+}
+
+// SelectorCommandHandler issues the command to the operational devices
+// selected by the selector, and returns the result of every device, sorted by
+// name. The error is only set when the selector is invalid or the command
+// failed for all the devices.
+func SelectorCommandHandler(ctx context.Context, selector dsModels.DeviceSelector, cmd string, body string, method string, queryParams string) ([]DeviceResult, common.AppError) {
+	common.LoggingClient.Debug(fmt.Sprintf("Handler - CommandAll: execute the %s command %s from the operational devices selected by %+v", method, cmd, selector))
+	if err := selector.Validate(); err != nil {
+		msg := fmt.Sprintf("Handler - CommandAll: invalid device selector: %v", err)
+		common.LoggingClient.Error(msg)
+		return nil, common.NewBadRequestError(msg, err)
+	}
+	if strings.ToLower(method) != common.GetCmdMethod {
+		if _, err := parseWriteMode(queryParams); err != nil {
+			msg := fmt.Sprintf("Handler - CommandAll: invalid write mode: %v", err)
+			common.LoggingClient.Error(msg)
+			return nil, common.NewBadRequestError(msg, err)
+		}
+	}
+	devices := filterOperationalDevices(selectDevices(selector))
+
+	results := commandDevices(ctx, devices, cmd, body, method, queryParams)
+	appErr := commandAllError(results)
+	if appErr == nil && len(results) > 0 {
+		common.LoggingClient.Info("Handler - CommandAll: part of commands executed successfully, returning 200 OK")
+	}
+	return results, appErr
+}
+
+// ParseDeviceSelector parses the device selector given by the ds-label,
+// ds-profile, ds-name and ds-property query parameters.
+func ParseDeviceSelector(queryParams string) (dsModels.DeviceSelector, error) {
+	var selector dsModels.DeviceSelector
+	values, err := url.ParseQuery(queryParams)
+	if err != nil {
+		return selector, err
+	}
+	for _, value := range values[common.LabelQueryParam] {
+		for _, label := range strings.Split(value, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				selector.Labels = append(selector.Labels, label)
+			}
+		}
+	}
+	selector.Profile = values.Get(common.ProfileQueryParam)
+	selector.NamePattern = values.Get(common.NameQueryParam)
+	for _, value := range values[common.PropertyQueryParam] {
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return selector, fmt.Errorf("protocol property %q must be name:value", value)
+		}
+		if selector.ProtocolProperties == nil {
+			selector.ProtocolProperties = make(map[string]string)
+		}
+		selector.ProtocolProperties[parts[0]] = parts[1]
+	}
+	return selector, selector.Validate()
+}
+
+// selectDevices returns the devices of the cache matched by the selector.
+func selectDevices(selector dsModels.DeviceSelector) []contract.Device {
+	devices := cache.Devices().All()
+	selected := devices[:0]
+	for _, d := range devices {
+		if selector.Matches(d) {
+			selected = append(selected, d)
+		}
+	}
+	return selected
+}
+
+// NewMultiStatusResponse converts the results of a command issued to several
+// devices into its response.
+func NewMultiStatusResponse(results []DeviceResult) dsModels.MultiStatusResponse {
+	response := dsModels.MultiStatusResponse{
+		StatusCode: http.StatusOK,
+		Devices:    make([]dsModels.DeviceCommandResponse, len(results)),
+	}
+	for i, r := range results {
+		response.Devices[i] = dsModels.DeviceCommandResponse{DeviceName: r.DeviceName, StatusCode: http.StatusOK, Event: r.Event}
+		if r.Err != nil {
+			response.Devices[i].StatusCode = r.Err.Code()
+			response.Devices[i].Message = r.Err.Message()
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+	if appErr := commandAllError(results); appErr != nil {
+		response.StatusCode = appErr.Code()
+	} else if response.Failed > 0 {
+		response.StatusCode = http.StatusMultiStatus
+	}
+	return response
+}
+
+// commandDevices issues the command to the devices through a pool of at most
+// Device.CommandAll MaxWorkers workers. The devices are passed to a driver
+// implementing BatchProtocolDriver in batches, one per worker call.
+func commandDevices(ctx context.Context, devices []*contract.Device, cmd string, body string, method string, queryParams string) []DeviceResult {
+	driver := batchDriver(method, queryParams)
+	batches := batchDevices(devices, driver != nil)
+	workers := common.CurrentConfig.Device.CommandAll.MaxWorkers
+	if workers <= 0 {
+		workers = defaultCommandAllWorkers
+	}
+	if workers > len(batches) {
+		workers = len(batches)
+	}
+
+	jobs := make(chan []*contract.Device)
+	// every device gets exactly one result
+	resultCh := make(chan DeviceResult, len(devices))
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer waitGroup.Done()
+			for batch := range jobs {
+				for _, r := range commandBatch(ctx, driver, batch, cmd, body, method, queryParams) {
+					resultCh <- r
+				}
+			}
+		}()
+	}
+	for _, batch := range batches {
+		jobs <- batch
+	}
+	close(jobs)
+	waitGroup.Wait()
+	close(resultCh)
+
+	results := make([]DeviceResult, 0, len(devices))
+	for r := range resultCh {
+		if r.Err != nil {
+			common.LoggingClient.Error("Handler - CommandAll: " + r.Err.Message())
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].DeviceName < results[j].DeviceName
+	})
+	return results
+}
+
+// batchDriver returns the driver as a BatchProtocolDriver, or nil when it
+// doesn't implement it or the command is a transactional write, which is
+// executed device by device.
+func batchDriver(method string, queryParams string) dsModels.BatchProtocolDriver {
+	driver, ok := common.Driver.(dsModels.BatchProtocolDriver)
+	if !ok {
+		return nil
+	}
+	if strings.ToLower(method) != common.GetCmdMethod {
+		if mode, _ := parseWriteMode(queryParams); mode == common.WriteModeTransactional {
+			return nil
+		}
+	}
+	return driver
+}
+
+// batchDevices splits the devices into the units of work of the workers,
+// which are single devices unless they are batched. In that case the devices
+// sharing the same protocol addresses are batched together, up to
+// Device.CommandAll MaxBatchSize devices. The devices having none of the
+// Concurrency AddressProperties are only batched with the devices having the
+// same protocol properties.
+func batchDevices(devices []*contract.Device, batched bool) [][]*contract.Device {
+	if !batched {
+		batches := make([][]*contract.Device, len(devices))
+		for i, device := range devices {
+			batches[i] = []*contract.Device{device}
+		}
+		return batches
+	}
+
+	groups := make(map[string][]*contract.Device)
+	var keys []string
+	for _, device := range devices {
+		key := batchKey(device)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], device)
+	}
+	sort.Strings(keys)
+
+	maxSize := common.CurrentConfig.Device.CommandAll.MaxBatchSize
+	var batches [][]*contract.Device
+	for _, key := range keys {
+		group := groups[key]
+		for maxSize > 0 && len(group) > maxSize {
+			batches = append(batches, group[:maxSize])
+			group = group[maxSize:]
+		}
+		batches = append(batches, group)
+	}
+	return batches
+}
+
+// batchKey returns the key of the devices which may be batched together,
+// which are their protocol addresses, or all their protocol properties when
+// they have none of the configured AddressProperties.
+func batchKey(device *contract.Device) string {
+	if addresses := protocolAddresses(device); len(addresses) > 0 {
+		return strings.Join(addresses, ",")
+	}
+
+	var properties []string
+	for protocol, pp := range device.Protocols {
+		for k, v := range pp {
+			properties = append(properties, fmt.Sprintf("%s/%s=%s", protocol, k, v))
+		}
+	}
+	sort.Strings(properties)
+	return strings.Join(properties, ",")
+}
+
+// commandBatch issues the command to a batch of devices and returns one result
+// per device. The devices are commanded one by one when driver is nil.
+func commandBatch(ctx context.Context, driver dsModels.BatchProtocolDriver, devices []*contract.Device, cmd string, body string, method string, queryParams string) []DeviceResult {
+	batched := driver != nil
+	read := strings.ToLower(method) == common.GetCmdMethod
+	// the write mode has been validated by SelectorCommandHandler
+	mode, _ := parseWriteMode(queryParams)
+
+	results := make([]DeviceResult, 0, len(devices))
+	var pending []*contract.Device
+	var reqs []dsModels.DeviceCommandRequests
+	for _, device := range devices {
+		common.InFlight.Begin(device.Name)
+		defer common.InFlight.End(device.Name)
+
+		result := DeviceResult{DeviceName: device.Name}
+		if _, ok := cache.Devices().ForName(device.Name); !ok {
+			result.Err = common.NewNotFoundError(fmt.Sprintf("Device: %s has been removed; %s", device.Name, method), nil)
+		} else if !batched && read {
+			result.Event, result.Err = execReadCmd(ctx, device, cmd, queryParams)
+		} else if !batched {
+			result.Err = execWriteCmd(ctx, device, cmd, body, mode)
+		} else {
+			r := dsModels.DeviceCommandRequests{DeviceName: device.Name, Protocols: device.Protocols}
+			var checks []writeCheck
+			if read {
+				r.Requests, result.Err = readRequests(device, cmd, queryParams)
+			} else {
+				r.Requests, r.Params, checks, result.Err = writeRequests(device, cmd, body)
+			}
+			if result.Err == nil && len(checks) > 0 {
+				// the verified parameters are read back once written, so
+				// they aren't batched
+				result.Err = writeDeviceCommands(ctx, device, cmd, r.Requests, r.Params, checks, mode)
+			} else if result.Err == nil {
+				pending = append(pending, device)
+				reqs = append(reqs, r)
+				continue
+			}
+		}
+		results = append(results, result)
+	}
+
+	if len(pending) > 0 {
+		results = append(results, handleBatchCommands(ctx, driver, pending, reqs, cmd, read)...)
+	}
+	return results
+}
+
+// handleBatchCommands passes the requests of several devices to the driver
+// in a single call, once the devices and their addresses are below their
+// concurrency limits.
+func handleBatchCommands(ctx context.Context, driver dsModels.BatchProtocolDriver, devices []*contract.Device, reqs []dsModels.DeviceCommandRequests, cmd string, read bool) []DeviceResult {
+	results := make([]DeviceResult, len(devices))
+	failAll := func(err error) []DeviceResult {
+		for i, device := range devices {
+			msg := fmt.Sprintf("Handler - CommandAll: error for Device: %s cmd: %s, %v", device.Name, cmd, err)
+			results[i] = DeviceResult{DeviceName: device.Name, Err: driverError(msg, err)}
+		}
+		return results
+	}
+
+	if err := ctx.Err(); err != nil {
+		return failAll(err)
+	}
+	release, err := AcquireDriver(ctx, devices...)
+	if err != nil {
+		return failAll(err)
+	}
+	var driverResults []dsModels.DeviceCommandResult
+	if read {
+		driverResults = driver.HandleBatchReadCommands(ctx, reqs)
+	} else {
+		driverResults = driver.HandleBatchWriteCommands(ctx, reqs)
+	}
+	release()
+
+	byName := make(map[string]dsModels.DeviceCommandResult, len(driverResults))
+	for _, r := range driverResults {
+		byName[r.DeviceName] = r
+	}
+	for i, device := range devices {
+		results[i].DeviceName = device.Name
+		r, ok := byName[device.Name]
+		if !ok {
+			r.Err = fmt.Errorf("no result returned by the driver")
+		}
+		if r.Err != nil {
+			msg := fmt.Sprintf("Handler - CommandAll: error for Device: %s cmd: %s, %v", device.Name, cmd, r.Err)
+			results[i].Err = driverError(msg, r.Err)
+		} else if read {
+			results[i].Event, results[i].Err = cvsToEvent(device, r.Values, cmd)
+		}
+	}
+	return results
+}
+
+// commandAllError returns an error summing up the failures when the command
+// failed for every device, whose status code is the one shared by all the
+// failures, otherwise 500.
+func commandAllError(results []DeviceResult) common.AppError {
+	if len(results) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Err == nil {
+			return nil
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %s", r.DeviceName, r.Err.Message()))
+	}
+	status := results[0].Err.Code()
+	for _, r := range results {
+		if r.Err.Code() != status {
+			status = http.StatusInternalServerError
+		}
+	}
+	msg := fmt.Sprintf("Handler - CommandAll: the command failed for all the %d devices; %s", len(results), strings.Join(msgs, "; "))
+	return common.NewAppError(msg, nil, status)
+}
@@ -0,0 +1,223 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// batchDriverMock executes the batched commands through DriverMock, and
+// records the devices of every batch.
+type batchDriverMock struct {
+	mock.DriverMock
+	mutex   sync.Mutex
+	batches [][]string
+}
+
+func (d *batchDriverMock) record(reqs []dsModels.DeviceCommandRequests) {
+	names := make([]string, len(reqs))
+	for i, r := range reqs {
+		names[i] = r.DeviceName
+	}
+	d.mutex.Lock()
+	d.batches = append(d.batches, names)
+	d.mutex.Unlock()
+}
+
+func (d *batchDriverMock) HandleBatchReadCommands(ctx context.Context, reqs []dsModels.DeviceCommandRequests) []dsModels.DeviceCommandResult {
+	d.record(reqs)
+	results := make([]dsModels.DeviceCommandResult, len(reqs))
+	for i, r := range reqs {
+		results[i].DeviceName = r.DeviceName
+		results[i].Values, results[i].Err = d.DriverMock.HandleReadCommands(r.DeviceName, r.Protocols, r.Requests)
+	}
+	return results
+}
+
+func (d *batchDriverMock) HandleBatchWriteCommands(ctx context.Context, reqs []dsModels.DeviceCommandRequests) []dsModels.DeviceCommandResult {
+	d.record(reqs)
+	// the result of the last device is missing
+	results := make([]dsModels.DeviceCommandResult, len(reqs)-1)
+	for i := range results {
+		results[i].DeviceName = reqs[i].DeviceName
+		results[i].Err = d.DriverMock.HandleWriteCommands(reqs[i].DeviceName, reqs[i].Protocols, reqs[i].Requests, reqs[i].Params)
+	}
+	return results
+}
+
+func TestBatchDevices(t *testing.T) {
+	defer func(driver dsModels.ProtocolDriver, info common.DeviceInfo) {
+		common.Driver = driver
+		common.CurrentConfig.Device = info
+	}(common.Driver, common.CurrentConfig.Device)
+	common.CurrentConfig.Device.Concurrency.AddressProperties = []string{"Address"}
+	common.CurrentConfig.Device.CommandAll.MaxBatchSize = 2
+
+	var devices []*contract.Device
+	for i := 0; i < 5; i++ {
+		devices = append(devices, &contract.Device{
+			Name:      fmt.Sprintf("Device-%d", i),
+			Protocols: map[string]contract.ProtocolProperties{"modbus-rtu": {"Address": fmt.Sprintf("/dev/tty%d", i%2)}},
+		})
+	}
+
+	// without BatchProtocolDriver, every device is commanded on its own
+	assert.Nil(t, batchDriver(methodGet, ""))
+	assert.Len(t, batchDevices(devices, false), 5)
+
+	common.Driver = &batchDriverMock{}
+	assert.NotNil(t, batchDriver(methodGet, ""))
+	// transactional writes aren't batched
+	assert.Nil(t, batchDriver(methodSet, "ds-write-mode=transactional"))
+	batches := batchDevices(devices, true)
+	require.Len(t, batches, 3)
+	assert.Equal(t, []*contract.Device{devices[0], devices[2]}, batches[0])
+	assert.Equal(t, []*contract.Device{devices[4]}, batches[1])
+	assert.Equal(t, []*contract.Device{devices[1], devices[3]}, batches[2])
+
+	// the devices without address aren't batched with unrelated ones
+	others := []*contract.Device{
+		{Name: "Other-0", Protocols: map[string]contract.ProtocolProperties{"other": {"Host": "a"}}},
+		{Name: "Other-1", Protocols: map[string]contract.ProtocolProperties{"other": {"Host": "b"}}},
+		{Name: "Other-2", Protocols: map[string]contract.ProtocolProperties{"other": {"Host": "a"}}},
+	}
+	batches = batchDevices(others, true)
+	require.Len(t, batches, 2)
+	assert.Equal(t, []*contract.Device{others[0], others[2]}, batches[0])
+	assert.Equal(t, []*contract.Device{others[1]}, batches[1])
+
+	// without AddressProperties, the devices are grouped by all their
+	// protocol properties
+	common.CurrentConfig.Device.Concurrency.AddressProperties = nil
+	devices[2].Protocols = map[string]contract.ProtocolProperties{"modbus-rtu": {"Address": "/dev/tty0", "UnitID": "2"}}
+	batches = batchDevices(devices, true)
+	require.Len(t, batches, 3)
+	assert.Equal(t, []*contract.Device{devices[0], devices[4]}, batches[0])
+	assert.Equal(t, []*contract.Device{devices[2]}, batches[1])
+	assert.Equal(t, []*contract.Device{devices[1], devices[3]}, batches[2])
+}
+
+func TestSelectorCommandHandlerBatch(t *testing.T) {
+	driver := &batchDriverMock{}
+	defer func(d dsModels.ProtocolDriver) {
+		common.Driver = d
+	}(common.Driver)
+	common.Driver = driver
+
+	results, appErr := SelectorCommandHandler(context.Background(), dsModels.DeviceSelector{}, "RandomValue_Uint8", "", methodGet, "")
+	require.Nil(t, appErr)
+	devices := filterOperationalDevices(cache.Devices().All())
+	require.Len(t, results, len(devices))
+	succeeded := 0
+	for _, r := range results {
+		if r.Err == nil {
+			succeeded++
+			assert.Equal(t, r.DeviceName, r.Event.Device)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	// the devices without the command aren't passed to the driver
+	require.Len(t, driver.batches, 1)
+	assert.Equal(t, []string{"Random-UnsignedInteger-Generator01"}, driver.batches[0])
+
+	results, appErr = SelectorCommandHandler(context.Background(), dsModels.DeviceSelector{}, "RandomValue_Uint8", `{"RandomValue_Uint8":"123"}`, methodSet, "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusInternalServerError, appErr.Code())
+	for _, r := range results {
+		assert.NotNil(t, r.Err, r.DeviceName)
+	}
+}
+
+func TestCommandAllError(t *testing.T) {
+	assert.Nil(t, commandAllError(nil))
+
+	notFound := common.NewNotFoundError("not found", nil)
+	results := []DeviceResult{{DeviceName: "A", Err: notFound}, {DeviceName: "B"}}
+	assert.Nil(t, commandAllError(results))
+
+	results[1].Err = notFound
+	appErr := commandAllError(results)
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.Code())
+	assert.Contains(t, appErr.Message(), "A: not found; B: not found")
+
+	results[1].Err = common.NewLockedError("locked", nil)
+	assert.Equal(t, http.StatusInternalServerError, commandAllError(results).Code())
+}
+
+func TestParseDeviceSelector(t *testing.T) {
+	selector, err := ParseDeviceSelector("ds-label=line-3,hvac&ds-label=floor-1&ds-profile=Thermostat&ds-name=Sensor-*&other=1")
+	require.NoError(t, err)
+	assert.Equal(t, dsModels.DeviceSelector{Labels: []string{"line-3", "hvac", "floor-1"}, Profile: "Thermostat", NamePattern: "Sensor-*"}, selector)
+
+	device := contract.Device{Name: "Sensor-01", Labels: []string{"floor-1", "hvac", "line-3"}, Profile: contract.DeviceProfile{Name: "Thermostat"}}
+	assert.True(t, selector.Matches(device))
+	assert.True(t, dsModels.DeviceSelector{}.Matches(device))
+	device.Labels = device.Labels[1:]
+	assert.False(t, selector.Matches(device))
+
+	_, err = ParseDeviceSelector("ds-name=[")
+	assert.Error(t, err)
+	_, appErr := SelectorCommandHandler(context.Background(), dsModels.DeviceSelector{NamePattern: "["}, "RandomValue_Uint8", "", methodGet, "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.Code())
+}
+
+func TestSelectorCommandHandler(t *testing.T) {
+	results, appErr := SelectorCommandHandler(context.Background(), dsModels.DeviceSelector{NamePattern: "Random-Unsigned*"}, "RandomValue_Uint8", "", methodGet, "")
+	require.Nil(t, appErr)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Random-UnsignedInteger-Generator01", results[0].DeviceName)
+	assert.Nil(t, results[0].Err)
+}
+
+func TestNewMultiStatusResponse(t *testing.T) {
+	event := &dsModels.Event{Event: contract.Event{Device: "A"}}
+	results := []DeviceResult{{DeviceName: "A", Event: event}, {DeviceName: "B", Err: common.NewLockedError("B is locked", nil)}}
+	response := NewMultiStatusResponse(results)
+	assert.Equal(t, http.StatusMultiStatus, response.StatusCode)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, []dsModels.DeviceCommandResponse{
+		{DeviceName: "A", StatusCode: http.StatusOK, Event: event},
+		{DeviceName: "B", StatusCode: http.StatusLocked, Message: "B is locked"},
+	}, response.Devices)
+
+	assert.Equal(t, http.StatusOK, NewMultiStatusResponse(results[:1]).StatusCode)
+	assert.Equal(t, http.StatusLocked, NewMultiStatusResponse(results[1:]).StatusCode)
+}
+
+func TestSelectorCommandHandlerProtocolProperties(t *testing.T) {
+	selector, err := ParseDeviceSelector("ds-property=Address:10.0.0.1:502")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Address": "10.0.0.1:502"}, selector.ProtocolProperties)
+	_, err = ParseDeviceSelector("ds-property=Address")
+	assert.Error(t, err)
+
+	device := contract.Device{Protocols: map[string]contract.ProtocolProperties{"modbus-tcp": {"Address": "10.0.0.1:502"}}}
+	assert.True(t, selector.Matches(device))
+	device.Protocols["modbus-tcp"]["Address"] = "10.0.0.2:502"
+	assert.False(t, selector.Matches(device))
+
+	// the devices of the cache don't have the property
+	results, appErr := SelectorCommandHandler(context.Background(), selector, "RandomValue_Uint8", "", methodGet, "")
+	assert.Nil(t, appErr)
+	assert.Empty(t, results)
+}
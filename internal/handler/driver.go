@@ -0,0 +1,137 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// busyError is returned when a driver call couldn't get a slot within the
+// configured concurrency limits.
+type busyError struct {
+	err error
+}
+
+func (e busyError) Error() string {
+	return e.err.Error()
+}
+
+// driverError wraps an error returned by handleReadCommands or
+// handleWriteCommands into the matching AppError.
+func driverError(msg string, err error) common.AppError {
+	if _, ok := err.(busyError); ok {
+		return common.NewServiceUnavailableError(msg, err)
+	}
+	return common.NewServerError(msg, err)
+}
+
+// AcquireDriver waits for the devices and their protocol addresses to be
+// below their concurrency limits. The returned function must be called once
+// the driver call has completed. Every driver call goes through it, including
+// the pings of the liveness monitor.
+func AcquireDriver(ctx context.Context, devices ...*contract.Device) (func(), error) {
+	if common.DriverLimiter == nil {
+		return func() {}, nil
+	}
+
+	config := common.CurrentConfig.Device.Concurrency
+	limits := make(map[string]int)
+	for _, device := range devices {
+		limits["device/"+device.Name] = config.MaxPerDevice
+		if config.MaxPerAddress > 0 {
+			for _, address := range protocolAddresses(device) {
+				limits["address/"+address] = config.MaxPerAddress
+			}
+		}
+	}
+
+	release, err := common.DriverLimiter.Acquire(ctx, limits)
+	if err != nil {
+		names := make([]string, len(devices))
+		for i, device := range devices {
+			names[i] = device.Name
+		}
+		common.LoggingClient.Warn(fmt.Sprintf("Handler - driver call for Device: %s rejected: %v", strings.Join(names, ", "), err))
+		return nil, busyError{err: err}
+	}
+	return release, nil
+}
+
+// protocolAddresses returns the addresses of the device, as protocol/address,
+// given by the Concurrency AddressProperties of its protocols.
+func protocolAddresses(device *contract.Device) []string {
+	protocols := make([]string, 0, len(device.Protocols))
+	for name := range device.Protocols {
+		protocols = append(protocols, name)
+	}
+	sort.Strings(protocols)
+
+	var addresses []string
+	for _, name := range protocols {
+		for _, property := range common.CurrentConfig.Device.Concurrency.AddressProperties {
+			if address, ok := device.Protocols[name][property]; ok && address != "" {
+				addresses = append(addresses, fmt.Sprintf("%s/%s", name, address))
+			}
+		}
+	}
+	return addresses
+}
+
+// handleReadCommands passes the read requests to the driver, through
+// ContextProtocolDriver when the driver implements it. Drivers which don't
+// support contexts aren't called at all once ctx is done.
+func handleReadCommands(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest) ([]*dsModels.CommandValue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	release, err := AcquireDriver(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return readCommands(ctx, device, reqs)
+}
+
+// handleWriteCommands passes the write requests to the driver, through
+// ContextProtocolDriver when the driver implements it.
+func handleWriteCommands(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	release, err := AcquireDriver(ctx, device)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return writeCommands(ctx, device, reqs, params)
+}
+
+// readCommands calls the driver once its slot has been acquired.
+func readCommands(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest) ([]*dsModels.CommandValue, error) {
+	if driver, ok := common.Driver.(dsModels.ContextProtocolDriver); ok {
+		return driver.HandleReadCommandsWithContext(ctx, device.Name, device.Protocols, reqs)
+	}
+	return common.Driver.HandleReadCommands(device.Name, device.Protocols, reqs)
+}
+
+// writeCommands calls the driver once its slot has been acquired.
+func writeCommands(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) error {
+	if driver, ok := common.Driver.(dsModels.ContextProtocolDriver); ok {
+		return driver.HandleWriteCommandsWithContext(ctx, device.Name, device.Protocols, reqs, params)
+	}
+	return common.Driver.HandleWriteCommands(device.Name, device.Protocols, reqs, params)
+}
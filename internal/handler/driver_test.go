@@ -0,0 +1,90 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/limiter"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+type contextDriverMock struct {
+	mock.DriverMock
+	correlation string
+	hasDeadline bool
+}
+
+func (d *contextDriverMock) HandleReadCommandsWithContext(ctx context.Context, deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest) ([]*dsModels.CommandValue, error) {
+	d.correlation = clients.FromContext(ctx, clients.CorrelationHeader)
+	_, d.hasDeadline = ctx.Deadline()
+	return d.DriverMock.HandleReadCommands(deviceName, protocols, reqs)
+}
+
+func (d *contextDriverMock) HandleWriteCommandsWithContext(ctx context.Context, deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) error {
+	d.correlation = clients.FromContext(ctx, clients.CorrelationHeader)
+	return ctx.Err()
+}
+
+func TestHandleCommandsWithContextDriver(t *testing.T) {
+	driver := &contextDriverMock{}
+	common.Driver = driver
+	common.CurrentConfig.Service.Timeout = 5000
+	defer func() {
+		common.Driver = &mock.DriverMock{}
+		common.CurrentConfig.Service.Timeout = 0
+	}()
+
+	ctx, cancel := common.NewCommandContext(context.Background(), "correlation-id")
+	defer cancel()
+	_, appErr := execReadCmd(ctx, &deviceIntegerGenerator, mock.ResourceObjectInt8, "")
+	require.Nil(t, appErr)
+	assert.Equal(t, "correlation-id", driver.correlation)
+	assert.True(t, driver.hasDeadline, "the Service.Timeout deadline must be passed to the driver")
+
+	driver.correlation = ""
+	assert.NoError(t, handleWriteCommands(ctx, &deviceIntegerGenerator, nil, nil))
+	assert.Equal(t, "correlation-id", driver.correlation)
+}
+
+func TestHandleCommandsWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := handleReadCommands(ctx, &deviceIntegerGenerator, nil)
+	assert.Equal(t, context.Canceled, err, "a legacy driver must not be called once the request is cancelled")
+	assert.Equal(t, context.Canceled, handleWriteCommands(ctx, &deviceIntegerGenerator, nil, nil))
+}
+
+func TestHandleCommandsOverConcurrencyLimit(t *testing.T) {
+	common.CurrentConfig.Device.Concurrency = common.ConcurrencyInfo{MaxPerDevice: 1}
+	common.DriverLimiter = limiter.New(5 * time.Millisecond)
+	defer func() {
+		common.CurrentConfig.Device.Concurrency = common.ConcurrencyInfo{}
+		common.DriverLimiter = nil
+	}()
+
+	release, err := AcquireDriver(context.Background(), &deviceIntegerGenerator)
+	require.NoError(t, err)
+	_, appErr := execReadCmd(context.Background(), &deviceIntegerGenerator, mock.ResourceObjectInt8, "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusServiceUnavailable, appErr.Code())
+
+	release()
+	_, appErr = execReadCmd(context.Background(), &deviceIntegerGenerator, mock.ResourceObjectInt8, "")
+	assert.Nil(t, appErr)
+}
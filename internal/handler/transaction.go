@@ -0,0 +1,145 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// parseWriteMode returns the write mode given by the ds-write-mode query
+// parameter, which is the default one when it isn't set.
+func parseWriteMode(queryParams string) (string, error) {
+	values, err := url.ParseQuery(queryParams)
+	if err != nil {
+		return "", err
+	}
+	switch mode := strings.ToLower(values.Get(common.WriteModeQueryParam)); mode {
+	case "", common.WriteModeDefault:
+		return common.WriteModeDefault, nil
+	case common.WriteModeTransactional:
+		return common.WriteModeTransactional, nil
+	default:
+		return "", fmt.Errorf("unknown write mode %q", mode)
+	}
+}
+
+// handleTransactionalWriteCommands writes all the parameters or none of them,
+// through TransactionalProtocolDriver when the driver implements it. Otherwise
+// the resources are read before being written, and their previous values are
+// written back if the write fails. The parameters of the checks are then read
+// back, and the previous values are written back as well if they don't match.
+func handleTransactionalWriteCommands(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue, checks []writeCheck) error {
+	previous, err := writeTransactional(ctx, device, reqs, params, len(checks) > 0)
+	if err != nil || len(checks) == 0 {
+		return err
+	}
+
+	// the driver slot is released while verifying, as the reads take their own
+	err = verifyWrites(ctx, device, checks)
+	if err == nil {
+		return nil
+	}
+	common.LoggingClient.Warn(fmt.Sprintf("Handler - transactional write for Device: %s not verified, restoring the previous values: %v", device.Name, err))
+
+	restoreCtx, cancel := restoreContext(ctx)
+	defer cancel()
+	release, acquireErr := AcquireDriver(restoreCtx, device)
+	if acquireErr != nil {
+		return fmt.Errorf("%v; restoring the previous values failed, the resources may be partially written: %v", err, acquireErr)
+	}
+	defer release()
+	return restorePreviousValues(restoreCtx, device, reqs, previous, err)
+}
+
+// writeTransactional writes the parameters all at once, and returns the
+// values of the resources before the write. They are only read when the
+// driver doesn't implement TransactionalProtocolDriver or keepPrevious is set.
+func writeTransactional(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue, keepPrevious bool) ([]*dsModels.CommandValue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	release, err := AcquireDriver(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	driver, transactional := common.Driver.(dsModels.TransactionalProtocolDriver)
+	if transactional && !keepPrevious {
+		return nil, writeTransaction(driver, device, reqs, params)
+	}
+
+	previous, err := readCommands(ctx, device, reqs)
+	if err == nil && len(previous) != len(reqs) {
+		err = fmt.Errorf("%d values read for %d resources", len(previous), len(reqs))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the values to restore on failure failed, nothing was written: %v", err)
+	}
+
+	if transactional {
+		return previous, writeTransaction(driver, device, reqs, params)
+	}
+	err = writeCommands(ctx, device, reqs, params)
+	if err == nil {
+		return previous, nil
+	}
+	common.LoggingClient.Warn(fmt.Sprintf("Handler - transactional write for Device: %s failed, restoring the previous values: %v", device.Name, err))
+
+	restoreCtx, cancel := restoreContext(ctx)
+	defer cancel()
+	return nil, restorePreviousValues(restoreCtx, device, reqs, previous, err)
+}
+
+// restoreContext returns the context of the writing back of the previous
+// values, which are restored even if the request has timed out.
+func restoreContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	correlationID, _ := ctx.Value(common.CorrelationHeader).(string)
+	return common.NewCommandContext(context.Background(), correlationID)
+}
+
+// restorePreviousValues writes back the previous values of the resources
+// after the write failed with err, the caller must hold the driver slot.
+func restorePreviousValues(ctx context.Context, device *contract.Device, reqs []dsModels.CommandRequest, previous []*dsModels.CommandValue, err error) error {
+	var restoreErr error
+	if driver, ok := common.Driver.(dsModels.TransactionalProtocolDriver); ok {
+		restoreErr = writeTransaction(driver, device, reqs, previous)
+	} else {
+		restoreErr = writeCommands(ctx, device, reqs, previous)
+	}
+	if restoreErr != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Handler - restoring the previous values of Device: %s failed, its resources may be partially written: %v", device.Name, restoreErr))
+		return fmt.Errorf("%v; restoring the previous values failed, the resources may be partially written: %v", err, restoreErr)
+	}
+	return fmt.Errorf("%v; the previous values were restored", err)
+}
+
+// writeTransaction prepares and commits the parameters, aborting the
+// transaction when the commit fails.
+func writeTransaction(driver dsModels.TransactionalProtocolDriver, device *contract.Device, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) error {
+	id, err := driver.PrepareWriteCommands(device.Name, device.Protocols, reqs, params)
+	if err != nil {
+		return fmt.Errorf("preparing the transaction failed, nothing was written: %v", err)
+	}
+	err = driver.CommitWriteCommands(device.Name, device.Protocols, id)
+	if err == nil {
+		return nil
+	}
+	if abortErr := driver.AbortWriteCommands(device.Name, device.Protocols, id); abortErr != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Handler - aborting transaction %s of Device: %s failed: %v", id, device.Name, abortErr))
+		return fmt.Errorf("committing the transaction failed: %v; aborting it failed: %v", err, abortErr)
+	}
+	return fmt.Errorf("committing the transaction failed, it was aborted: %v", err)
+}
@@ -0,0 +1,144 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// compensationDriverMock reads 1 for every resource, and fails its first
+// failWrites writes.
+type compensationDriverMock struct {
+	mock.DriverMock
+	failWrites int
+	writes     [][]*dsModels.CommandValue
+}
+
+func (d *compensationDriverMock) HandleReadCommands(deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest) ([]*dsModels.CommandValue, error) {
+	values := make([]*dsModels.CommandValue, len(reqs))
+	for i, req := range reqs {
+		values[i], _ = dsModels.NewInt32Value(req.DeviceResourceName, 0, 1)
+	}
+	return values, nil
+}
+
+func (d *compensationDriverMock) HandleWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) error {
+	d.writes = append(d.writes, params)
+	if len(d.writes) <= d.failWrites {
+		return errors.New("device busy")
+	}
+	return nil
+}
+
+type transactionalDriverMock struct {
+	mock.DriverMock
+	commitErr error
+	calls     []string
+}
+
+func (d *transactionalDriverMock) PrepareWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest, params []*dsModels.CommandValue) (string, error) {
+	d.calls = append(d.calls, "prepare")
+	return "tx-1", nil
+}
+
+func (d *transactionalDriverMock) CommitWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, transactionID string) error {
+	d.calls = append(d.calls, "commit "+transactionID)
+	return d.commitErr
+}
+
+func (d *transactionalDriverMock) AbortWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, transactionID string) error {
+	d.calls = append(d.calls, "abort "+transactionID)
+	return nil
+}
+
+func TestParseWriteMode(t *testing.T) {
+	mode, err := parseWriteMode("")
+	require.NoError(t, err)
+	assert.Equal(t, common.WriteModeDefault, mode)
+	mode, err = parseWriteMode("ds-write-mode=Transactional&other=1")
+	require.NoError(t, err)
+	assert.Equal(t, common.WriteModeTransactional, mode)
+	_, err = parseWriteMode("ds-write-mode=atomic")
+	assert.Error(t, err)
+}
+
+func TestTransactionalWriteCompensation(t *testing.T) {
+	defer func(d dsModels.ProtocolDriver) {
+		common.Driver = d
+	}(common.Driver)
+	reqs := []dsModels.CommandRequest{{DeviceResourceName: "A", Type: dsModels.Int32}, {DeviceResourceName: "B", Type: dsModels.Int32}}
+	a, _ := dsModels.NewInt32Value("A", 0, 10)
+	b, _ := dsModels.NewInt32Value("B", 0, 20)
+	params := []*dsModels.CommandValue{a, b}
+
+	driver := &compensationDriverMock{}
+	common.Driver = driver
+	require.NoError(t, handleTransactionalWriteCommands(context.Background(), &deviceIntegerGenerator, reqs, params, nil))
+	assert.Len(t, driver.writes, 1)
+
+	// the previous values are written back when the write fails
+	driver = &compensationDriverMock{failWrites: 1}
+	common.Driver = driver
+	err := handleTransactionalWriteCommands(context.Background(), &deviceIntegerGenerator, reqs, params, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the previous values were restored")
+	require.Len(t, driver.writes, 2)
+	assert.Equal(t, "1", driver.writes[1][0].ValueToString())
+	assert.Equal(t, "B", driver.writes[1][1].DeviceResourceName)
+
+	driver = &compensationDriverMock{failWrites: 2}
+	common.Driver = driver
+	err = handleTransactionalWriteCommands(context.Background(), &deviceIntegerGenerator, reqs, params, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may be partially written")
+}
+
+func TestTransactionalWriteVerification(t *testing.T) {
+	defer func(d dsModels.ProtocolDriver) {
+		common.Driver = d
+	}(common.Driver)
+	reqs := []dsModels.CommandRequest{{DeviceResourceName: "A", Type: dsModels.Int32}}
+	a, _ := dsModels.NewInt32Value("A", 0, 10)
+	written, _ := dsModels.NewInt32Value("A", 0, 10)
+	checks := []writeCheck{{req: reqs[0], dr: contract.DeviceResource{Name: "A"}, written: written}}
+
+	// the device reads back 1 rather than the parameter, which is undone
+	driver := &compensationDriverMock{}
+	common.Driver = driver
+	err := handleTransactionalWriteCommands(context.Background(), &deviceIntegerGenerator, reqs, []*dsModels.CommandValue{a}, checks)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "A: 10 written but 1 read back")
+	assert.Contains(t, err.Error(), "the previous values were restored")
+	require.Len(t, driver.writes, 2)
+	assert.Equal(t, "1", driver.writes[1][0].ValueToString())
+}
+
+func TestTransactionalWriteDriver(t *testing.T) {
+	defer func(d dsModels.ProtocolDriver) {
+		common.Driver = d
+	}(common.Driver)
+
+	driver := &transactionalDriverMock{}
+	common.Driver = driver
+	require.NoError(t, handleTransactionalWriteCommands(context.Background(), &deviceIntegerGenerator, nil, nil, nil))
+	assert.Equal(t, []string{"prepare", "commit tx-1"}, driver.calls)
+
+	driver = &transactionalDriverMock{commitErr: errors.New("commit failed")}
+	common.Driver = driver
+	assert.Error(t, handleTransactionalWriteCommands(context.Background(), &deviceIntegerGenerator, nil, nil, nil))
+	assert.Equal(t, []string{"prepare", "commit tx-1", "abort tx-1"}, driver.calls)
+}
@@ -0,0 +1,188 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/transformer"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// writeCheck is the verification of a parameter written to a device
+// resource, which is read back once the write has succeeded.
+type writeCheck struct {
+	req dsModels.CommandRequest
+	dr  contract.DeviceResource
+	// written is the parameter before the transforms
+	written *dsModels.CommandValue
+	// tolerance is the maximum difference of a numeric value, absolute or a
+	// percentage of the parameter if tolerancePercent is set
+	tolerance        float64
+	tolerancePercent bool
+	retries          int
+	interval         time.Duration
+}
+
+// verifiedCommand tells whether the ds-verify attribute of the device
+// resource enables the verification of the parameters written by cmd.
+func verifiedCommand(dr contract.DeviceResource, cmd string) bool {
+	value, ok := dr.Attributes[common.VerifyAttribute]
+	if !ok {
+		return false
+	}
+	if enabled, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+		return enabled
+	}
+	for _, c := range strings.Split(value, ",") {
+		if strings.TrimSpace(c) == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// newWriteCheck returns the verification of the parameter cv, which must be
+// called before cv is transformed, with the settings of Device.WriteVerification
+// overridden by the attributes of the device resource.
+func newWriteCheck(dr contract.DeviceResource, req dsModels.CommandRequest, cv *dsModels.CommandValue) (writeCheck, error) {
+	config := common.CurrentConfig.Device.WriteVerification
+	written := *cv
+	written.NumericValue = append([]byte(nil), cv.NumericValue...)
+	written.BinValue = append([]byte(nil), cv.BinValue...)
+	check := writeCheck{req: req, dr: dr, written: &written, retries: config.Retries}
+
+	if value, ok := dr.Attributes[common.VerifyToleranceAttribute]; ok {
+		check.tolerancePercent = strings.HasSuffix(value, "%")
+		tolerance, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "%")), 64)
+		if err != nil || tolerance < 0 {
+			return check, fmt.Errorf("invalid verification tolerance %q of device resource %s", value, dr.Name)
+		}
+		check.tolerance = tolerance
+	}
+	if value, ok := dr.Attributes[common.VerifyRetriesAttribute]; ok {
+		retries, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || retries < 0 {
+			return check, fmt.Errorf("invalid verification retries %q of device resource %s", value, dr.Name)
+		}
+		check.retries = retries
+	}
+	interval := config.RetryInterval
+	if value, ok := dr.Attributes[common.VerifyIntervalAttribute]; ok {
+		interval = value
+	}
+	if interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < 0 {
+			return check, fmt.Errorf("invalid verification interval %q of device resource %s", interval, dr.Name)
+		}
+		check.interval = d
+	}
+	return check, nil
+}
+
+// verify compares the value read back to the written parameter, once it has
+// been transformed like a reading. It returns a description of the mismatch,
+// empty if the values match.
+func (c writeCheck) verify(read *dsModels.CommandValue) string {
+	if !read.IsGood() {
+		return fmt.Sprintf("%s: the value read back has %s quality (%s)", c.dr.Name, read.Quality, read.QualityReason)
+	}
+	if common.CurrentConfig.Device.DataTransform {
+		err := transformer.TransformReadResult(read, c.dr.Properties.Value)
+		if err == nil {
+			err = transformer.TransformReadExpression(read, c.dr)
+		}
+		if err != nil {
+			return fmt.Sprintf("%s: transforming the value read back failed: %v", c.dr.Name, err)
+		}
+	}
+	ok, err := transformer.VerifyWrittenValue(c.written, read, c.tolerance, c.tolerancePercent)
+	if err != nil {
+		return fmt.Sprintf("%s: comparing the value read back failed: %v", c.dr.Name, err)
+	}
+	if !ok {
+		return fmt.Sprintf("%s: %s written but %s read back", c.dr.Name, c.written.ValueToString(), read.ValueToString())
+	}
+	return ""
+}
+
+// verifyWrites reads back the device resources of the checks, until their
+// values match the written parameters or their retries are exhausted. The
+// parameters aren't written again, and are only restored on failure in the
+// transactional write mode.
+func verifyWrites(ctx context.Context, device *contract.Device, checks []writeCheck) error {
+	for attempt := 0; len(checks) > 0; attempt++ {
+		if attempt > 0 {
+			var interval time.Duration
+			for _, c := range checks {
+				if c.interval > interval {
+					interval = c.interval
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("write verification interrupted: %v", ctx.Err())
+			case <-time.After(interval):
+			}
+		}
+
+		var mismatches []string
+		checks, mismatches = readBack(ctx, device, checks, attempt)
+		if len(mismatches) > 0 {
+			return fmt.Errorf("write verification failed, the device did not accept the parameters: %s", strings.Join(mismatches, "; "))
+		}
+	}
+	return nil
+}
+
+// readBack reads the device resources of the checks once, and returns the
+// checks which failed and can be retried, and the mismatches of the ones which
+// can't.
+func readBack(ctx context.Context, device *contract.Device, checks []writeCheck, attempt int) ([]writeCheck, []string) {
+	reqs := make([]dsModels.CommandRequest, len(checks))
+	for i, c := range checks {
+		reqs[i] = c.req
+	}
+	cvs, err := handleReadCommands(ctx, device, reqs)
+	values := make(map[string]*dsModels.CommandValue, len(cvs))
+	for _, cv := range cvs {
+		if cv != nil {
+			values[cv.DeviceResourceName] = cv
+		}
+	}
+
+	var retry []writeCheck
+	var mismatches []string
+	for _, c := range checks {
+		var mismatch string
+		if err != nil {
+			mismatch = fmt.Sprintf("%s: reading back failed: %v", c.dr.Name, err)
+		} else if cv, ok := values[c.dr.Name]; !ok {
+			mismatch = fmt.Sprintf("%s: no value read back", c.dr.Name)
+		} else {
+			mismatch = c.verify(cv)
+		}
+		if mismatch == "" {
+			continue
+		}
+		if attempt < c.retries {
+			common.LoggingClient.Debug(fmt.Sprintf("Handler - write verification of Device: %s attempt %d, %s", device.Name, attempt+1, mismatch))
+			retry = append(retry, c)
+		} else {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	return retry, mismatches
+}
@@ -0,0 +1,126 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// readBackDriverMock reads the successive values of its sequence for every
+// resource, the last one being repeated.
+type readBackDriverMock struct {
+	mock.DriverMock
+	values []int32
+	reads  int
+}
+
+func (d *readBackDriverMock) HandleReadCommands(deviceName string, protocols map[string]contract.ProtocolProperties, reqs []dsModels.CommandRequest) ([]*dsModels.CommandValue, error) {
+	v := d.values[len(d.values)-1]
+	if d.reads < len(d.values) {
+		v = d.values[d.reads]
+	}
+	d.reads++
+	values := make([]*dsModels.CommandValue, len(reqs))
+	for i, req := range reqs {
+		values[i], _ = dsModels.NewInt32Value(req.DeviceResourceName, 0, v)
+	}
+	return values, nil
+}
+
+func TestVerifiedCommand(t *testing.T) {
+	dr := contract.DeviceResource{Name: "Valve", Attributes: map[string]string{}}
+	assert.False(t, verifiedCommand(dr, "Valve"))
+	dr.Attributes[common.VerifyAttribute] = "true"
+	assert.True(t, verifiedCommand(dr, "Valve"))
+	dr.Attributes[common.VerifyAttribute] = "false"
+	assert.False(t, verifiedCommand(dr, "Valve"))
+	dr.Attributes[common.VerifyAttribute] = "OpenValve, CloseValve"
+	assert.True(t, verifiedCommand(dr, "CloseValve"))
+	assert.False(t, verifiedCommand(dr, "Valve"))
+}
+
+func TestNewWriteCheck(t *testing.T) {
+	cv, _ := dsModels.NewInt32Value("Valve", 0, 10)
+	dr := contract.DeviceResource{Name: "Valve", Attributes: map[string]string{
+		common.VerifyToleranceAttribute: "5%",
+		common.VerifyRetriesAttribute:   "4",
+		common.VerifyIntervalAttribute:  "10ms",
+	}}
+	check, err := newWriteCheck(dr, dsModels.CommandRequest{DeviceResourceName: "Valve"}, cv)
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), check.tolerance)
+	assert.True(t, check.tolerancePercent)
+	assert.Equal(t, 4, check.retries)
+	assert.Equal(t, 10*time.Millisecond, check.interval)
+
+	// the written parameter is kept before it is transformed
+	cv.NumericValue[len(cv.NumericValue)-1] = 0
+	assert.Equal(t, "10", check.written.ValueToString())
+
+	for _, attributes := range []map[string]string{
+		{common.VerifyToleranceAttribute: "-1"},
+		{common.VerifyRetriesAttribute: "many"},
+		{common.VerifyIntervalAttribute: "soon"},
+	} {
+		_, err = newWriteCheck(contract.DeviceResource{Name: "Valve", Attributes: attributes}, dsModels.CommandRequest{}, cv)
+		assert.Error(t, err, attributes)
+	}
+}
+
+func TestVerifyWrites(t *testing.T) {
+	defer func(d dsModels.ProtocolDriver, dataTransform bool) {
+		common.Driver = d
+		common.CurrentConfig.Device.DataTransform = dataTransform
+	}(common.Driver, common.CurrentConfig.Device.DataTransform)
+	common.CurrentConfig.Device.DataTransform = true
+
+	written, _ := dsModels.NewInt32Value("Valve", 0, 10)
+	check := writeCheck{
+		req:     dsModels.CommandRequest{DeviceResourceName: "Valve", Type: dsModels.Int32},
+		dr:      contract.DeviceResource{Name: "Valve"},
+		written: written,
+		retries: 2,
+	}
+
+	driver := &readBackDriverMock{values: []int32{10}}
+	common.Driver = driver
+	require.NoError(t, verifyWrites(context.Background(), &deviceIntegerGenerator, []writeCheck{check}))
+	assert.Equal(t, 1, driver.reads)
+
+	// the value is read again until it matches
+	driver = &readBackDriverMock{values: []int32{0, 0, 10}}
+	common.Driver = driver
+	require.NoError(t, verifyWrites(context.Background(), &deviceIntegerGenerator, []writeCheck{check}))
+	assert.Equal(t, 3, driver.reads)
+
+	driver = &readBackDriverMock{values: []int32{0}}
+	common.Driver = driver
+	err := verifyWrites(context.Background(), &deviceIntegerGenerator, []writeCheck{check})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Valve: 10 written but 0 read back")
+	assert.Equal(t, 3, driver.reads)
+
+	// the tolerance applies to the value read back once transformed
+	check.dr.Properties.Value.Scale = "2"
+	check.tolerance = 1
+	driver = &readBackDriverMock{values: []int32{5}}
+	common.Driver = driver
+	assert.NoError(t, verifyWrites(context.Background(), &deviceIntegerGenerator, []writeCheck{check}))
+	driver = &readBackDriverMock{values: []int32{6}}
+	common.Driver = driver
+	assert.Error(t, verifyWrites(context.Background(), &deviceIntegerGenerator, []writeCheck{check}))
+}
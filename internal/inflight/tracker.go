@@ -0,0 +1,92 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package inflight keeps a reference count of the operations in progress on
+// each device, so that a device isn't removed or reconfigured in the driver
+// while commands or autoevents are still being executed against it.
+package inflight
+
+import (
+	"sync"
+	"time"
+)
+
+type device struct {
+	count   int
+	drained chan struct{}
+}
+
+// Tracker counts the operations in progress per device name.
+type Tracker struct {
+	mutex   sync.Mutex
+	devices map[string]*device
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{devices: make(map[string]*device)}
+}
+
+// Begin marks the start of an operation on the device. Every call must be
+// matched by a call to End.
+func (t *Tracker) Begin(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	d, ok := t.devices[name]
+	if !ok {
+		d = &device{drained: make(chan struct{})}
+		t.devices[name] = d
+	}
+	d.count++
+}
+
+// End marks the completion of an operation on the device.
+func (t *Tracker) End(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	d, ok := t.devices[name]
+	if !ok {
+		return
+	}
+	d.count--
+	if d.count <= 0 {
+		close(d.drained)
+		delete(t.devices, name)
+	}
+}
+
+// Count returns the number of operations in progress on the device.
+func (t *Tracker) Count(name string) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if d, ok := t.devices[name]; ok {
+		return d.count
+	}
+	return 0
+}
+
+// Wait blocks until no operation is in progress on the device or the timeout
+// has elapsed, and reports whether the device was drained.
+func (t *Tracker) Wait(name string, timeout time.Duration) bool {
+	t.mutex.Lock()
+	d, ok := t.devices[name]
+	t.mutex.Unlock()
+	if !ok {
+		return true
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-d.drained:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inflight
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_WaitForDrain(t *testing.T) {
+	tracker := NewTracker()
+	assert.True(t, tracker.Wait("device", time.Millisecond), "a device without operations is drained")
+
+	tracker.Begin("device")
+	tracker.Begin("device")
+	assert.Equal(t, 2, tracker.Count("device"))
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		tracker.End("device")
+		tracker.End("device")
+	}()
+	assert.True(t, tracker.Wait("device", time.Second))
+	assert.Equal(t, 0, tracker.Count("device"))
+}
+
+func TestTracker_WaitTimeout(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Begin("device")
+	defer tracker.End("device")
+
+	assert.False(t, tracker.Wait("device", 5*time.Millisecond))
+	assert.True(t, tracker.Wait("other", 5*time.Millisecond))
+}
@@ -0,0 +1,204 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package limiter bounds the number of concurrent driver calls per key, e.g.
+// per device or per protocol address. Callers over the limit are queued in
+// FIFO order and give up once the maximum wait time has elapsed.
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is returned by Acquire when the maximum wait time has elapsed.
+type ErrTimeout struct {
+	Key  string
+	Wait time.Duration
+}
+
+func (e ErrTimeout) Error() string {
+	return fmt.Sprintf("timed out after %v waiting for a free slot on %s", e.Wait, e.Key)
+}
+
+// Metrics is a snapshot of the limiter counters.
+type Metrics struct {
+	// Waiting is the number of callers currently queued.
+	Waiting int
+	// Acquired is the number of slots granted.
+	Acquired uint64
+	// Queued is the number of slots which were only granted after waiting.
+	Queued uint64
+	// Rejected is the number of callers which gave up waiting.
+	Rejected uint64
+	// TotalWait is the accumulated time spent waiting for a slot.
+	TotalWait time.Duration
+	// MaxWait is the longest time spent waiting for a slot.
+	MaxWait time.Duration
+}
+
+type slot struct {
+	limit   int
+	active  int
+	waiters []chan struct{}
+}
+
+// Limiter holds one semaphore per key.
+type Limiter struct {
+	maxWait time.Duration
+	mutex   sync.Mutex
+	slots   map[string]*slot
+	metrics Metrics
+}
+
+// New creates a Limiter whose callers wait at most maxWait for their slots,
+// 0 means they wait until their context is done.
+func New(maxWait time.Duration) *Limiter {
+	return &Limiter{maxWait: maxWait, slots: make(map[string]*slot)}
+}
+
+// Acquire takes a slot on every key, each limited to the number of concurrent
+// holders given in limits, waiting at most the Limiter maximum wait overall. Keys are always taken in the same order so that callers
+// sharing several keys can't deadlock. The returned function releases all the
+// slots and must be called exactly once.
+func (l *Limiter) Acquire(ctx context.Context, limits map[string]int) (func(), error) {
+	keys := make([]string, 0, len(limits))
+	for k, limit := range limits {
+		if limit > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	if l.maxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.maxWait)
+		defer cancel()
+	}
+
+	start := time.Now()
+	queued := false
+	acquired := make([]string, 0, len(keys))
+	release := func() {
+		for _, k := range acquired {
+			l.release(k)
+		}
+	}
+	for _, k := range keys {
+		waited, err := l.acquire(ctx, k, limits[k])
+		queued = queued || waited
+		if err != nil {
+			release()
+			wait := time.Since(start)
+			l.record(wait, queued, false)
+			if err == context.DeadlineExceeded && l.maxWait > 0 {
+				return nil, ErrTimeout{Key: k, Wait: wait}
+			}
+			return nil, err
+		}
+		acquired = append(acquired, k)
+	}
+	l.record(time.Since(start), queued, true)
+	return release, nil
+}
+
+// Metrics returns a snapshot of the limiter counters.
+func (l *Limiter) Metrics() Metrics {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	m := l.metrics
+	m.Waiting = 0
+	for _, s := range l.slots {
+		m.Waiting += len(s.waiters)
+	}
+	return m
+}
+
+// acquire takes a slot on key and reports whether the caller had to wait.
+func (l *Limiter) acquire(ctx context.Context, key string, limit int) (bool, error) {
+	l.mutex.Lock()
+	s, ok := l.slots[key]
+	if !ok {
+		s = &slot{}
+		l.slots[key] = s
+	}
+	s.limit = limit
+	if s.active < s.limit && len(s.waiters) == 0 {
+		s.active++
+		l.mutex.Unlock()
+		return false, nil
+	}
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	l.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return true, nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		select {
+		case <-ready:
+			// the slot was handed over while giving up, pass it on
+			l.handOver(key, s)
+		default:
+			for i, w := range s.waiters {
+				if w == ready {
+					s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		return true, ctx.Err()
+	}
+}
+
+func (l *Limiter) release(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.handOver(key, l.slots[key])
+}
+
+// handOver gives the slot held by the caller to the next waiter, or frees it.
+// It must be called with the mutex held.
+func (l *Limiter) handOver(key string, s *slot) {
+	if len(s.waiters) > 0 && s.active <= s.limit {
+		next := s.waiters[0]
+		s.waiters = s.waiters[1:]
+		close(next)
+		return
+	}
+	s.active--
+	if s.active == 0 && len(s.waiters) == 0 {
+		delete(l.slots, key)
+	}
+}
+
+func (l *Limiter) record(wait time.Duration, queued bool, acquired bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if acquired {
+		l.metrics.Acquired++
+		if queued {
+			l.metrics.Queued++
+		}
+	} else {
+		l.metrics.Rejected++
+	}
+	if queued {
+		l.metrics.TotalWait += wait
+		if wait > l.metrics.MaxWait {
+			l.metrics.MaxWait = wait
+		}
+	}
+}
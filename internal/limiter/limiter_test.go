@@ -0,0 +1,102 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package limiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_SerializesCallsPerKey(t *testing.T) {
+	l := New(0)
+	limits := map[string]int{"device/modbus": 1}
+
+	var mutex sync.Mutex
+	active, maxActive := 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background(), limits)
+			require.NoError(t, err)
+			mutex.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			mutex.Unlock()
+			time.Sleep(2 * time.Millisecond)
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maxActive)
+	m := l.Metrics()
+	assert.Equal(t, uint64(5), m.Acquired)
+	assert.True(t, m.Queued > 0)
+	assert.True(t, m.MaxWait > 0)
+	assert.Equal(t, 0, m.Waiting)
+}
+
+func TestLimiter_MaxWait(t *testing.T) {
+	l := New(10 * time.Millisecond)
+	limits := map[string]int{"device/modbus": 1}
+
+	release, err := l.Acquire(context.Background(), limits)
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background(), limits)
+	require.Error(t, err)
+	_, ok := err.(ErrTimeout)
+	assert.True(t, ok, "expected ErrTimeout, got %v", err)
+	assert.Equal(t, uint64(1), l.Metrics().Rejected)
+
+	// the rejected caller must not hold on to the slot
+	release()
+	release, err = l.Acquire(context.Background(), limits)
+	require.NoError(t, err)
+	release()
+}
+
+func TestLimiter_SharedAddressAcrossDevices(t *testing.T) {
+	l := New(10 * time.Millisecond)
+
+	release, err := l.Acquire(context.Background(), map[string]int{"device/a": 1, "address/modbus-rtu//dev/ttyUSB0": 1})
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background(), map[string]int{"device/b": 1, "address/modbus-rtu//dev/ttyUSB0": 1})
+	assert.Error(t, err, "devices on the same serial port must be serialized")
+	assert.Equal(t, 0, l.Metrics().Waiting)
+
+	other, err := l.Acquire(context.Background(), map[string]int{"device/c": 1, "address/modbus-rtu//dev/ttyUSB1": 1})
+	assert.NoError(t, err)
+	other()
+	release()
+}
+
+func TestLimiter_CancelledContext(t *testing.T) {
+	l := New(0)
+	limits := map[string]int{"device/modbus": 1}
+	release, err := l.Acquire(context.Background(), limits)
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx, limits)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
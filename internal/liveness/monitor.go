@@ -0,0 +1,284 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package liveness pings the devices periodically through the driver to track
+// their reachability, and reports the devices which go offline or come back.
+package liveness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/handler"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// errBusy is returned by ping when the device is at its concurrency limits,
+// the device is pinged again at the next Interval.
+var errBusy = errors.New("device busy")
+
+// Config holds the parsed Device.Liveness settings.
+type Config struct {
+	Interval             time.Duration
+	Timeout              time.Duration
+	FailureThreshold     int
+	UpdateOperatingState bool
+}
+
+// Monitor pings the devices periodically and tracks their reachability.
+type Monitor struct {
+	pinger dsModels.DevicePinger
+	config Config
+	mutex  sync.Mutex
+	states map[string]*deviceState
+}
+
+type deviceState struct {
+	// known is set once the reachability of the device has been determined
+	known     bool
+	reachable bool
+	failures  int
+	lastSeen  time.Time
+	// pinging is set while a Ping of the device is in progress, even if it
+	// has timed out, so that pings don't pile up on a hung device
+	pinging bool
+}
+
+// NewMonitor creates a Monitor pinging the devices through pinger.
+func NewMonitor(pinger dsModels.DevicePinger, config Config) *Monitor {
+	if config.Timeout <= 0 || config.Timeout > config.Interval {
+		config.Timeout = config.Interval
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 1
+	}
+	return &Monitor{pinger: pinger, config: config, states: make(map[string]*deviceState)}
+}
+
+// Run pings the devices every Interval until ctx is done, the caller must
+// have added Run to wg.
+func (m *Monitor) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	m.pingAll(ctx, wg)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.pingAll(ctx, wg)
+		}
+	}
+}
+
+// pingAll pings concurrently the devices in cache which aren't locked and
+// whose previous ping has completed.
+func (m *Monitor) pingAll(ctx context.Context, wg *sync.WaitGroup) {
+	devices := cache.Devices().All()
+	present := make(map[string]bool, len(devices))
+
+	m.mutex.Lock()
+	var pinged []contract.Device
+	for _, d := range devices {
+		present[d.Name] = true
+		if d.AdminState == contract.Locked {
+			continue
+		}
+		s := m.state(d.Name)
+		if s.pinging {
+			common.LoggingClient.Debug(fmt.Sprintf("Liveness - previous ping of device %s still in progress", d.Name))
+			continue
+		}
+		s.pinging = true
+		pinged = append(pinged, d)
+	}
+	// forget the devices which have been removed
+	for name := range m.states {
+		if !present[name] {
+			delete(m.states, name)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, d := range pinged {
+		wg.Add(1)
+		go func(d contract.Device) {
+			defer wg.Done()
+			err := m.ping(ctx, d)
+			m.record(d, err, time.Now())
+		}(d)
+	}
+}
+
+// ping calls the driver, giving up after Timeout. Like the commands, the ping
+// waits for the concurrency limits of the device and is tracked as in flight,
+// so that device updates wait for it to complete.
+func (m *Monitor) ping(ctx context.Context, d contract.Device) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	release, err := handler.AcquireDriver(ctx, &d)
+	if err != nil {
+		m.pinged(d.Name)
+		if ctx.Err() == context.Canceled {
+			return ctx.Err()
+		}
+		common.LoggingClient.Debug(fmt.Sprintf("Liveness - ping of device %s skipped: %v", d.Name, err))
+		return errBusy
+	}
+	common.InFlight.Begin(d.Name)
+
+	result := make(chan error, 1)
+	go func() {
+		err := m.pinger.Ping(d.Name, d.Protocols)
+		common.InFlight.End(d.Name)
+		release()
+		m.pinged(d.Name)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("ping timed out after %v", m.config.Timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// pinged marks the ping of the device as completed.
+func (m *Monitor) pinged(deviceName string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if s, ok := m.states[deviceName]; ok {
+		s.pinging = false
+	}
+}
+
+// record updates the reachability of the device with the result of a ping,
+// and reports the change if any.
+func (m *Monitor) record(d contract.Device, err error, now time.Time) {
+	if err == context.Canceled || err == errBusy {
+		return
+	}
+
+	m.mutex.Lock()
+	s := m.state(d.Name)
+	changed := false
+	if err == nil {
+		changed = s.known && !s.reachable
+		s.known = true
+		s.reachable = true
+		s.failures = 0
+		s.lastSeen = now
+	} else {
+		s.failures++
+		common.LoggingClient.Debug(fmt.Sprintf("Liveness - ping of device %s failed (%d): %v", d.Name, s.failures, err))
+		if s.failures >= m.config.FailureThreshold && (!s.known || s.reachable) {
+			changed = true
+			s.known = true
+			s.reachable = false
+		}
+	}
+	m.mutex.Unlock()
+
+	if err == nil {
+		common.UpdateLastConnected(d.Name)
+	}
+	if changed {
+		m.reachabilityChanged(d.Name, err == nil, err)
+	}
+}
+
+// reachabilityChanged updates the OperatingState of the device as configured,
+// and notifies the subscribers of the change.
+func (m *Monitor) reachabilityChanged(deviceName string, reachable bool, err error) {
+	if reachable {
+		common.LoggingClient.Info(fmt.Sprintf("Liveness - device %s is reachable again", deviceName))
+	} else {
+		common.LoggingClient.Warn(fmt.Sprintf("Liveness - device %s is unreachable: %v", deviceName, err))
+	}
+
+	if m.config.UpdateOperatingState {
+		m.updateOperatingState(deviceName, reachable)
+	}
+	liveness, _ := m.Liveness(deviceName)
+	common.Notify(dsModels.Notification{Type: dsModels.DeviceLivenessChanged, Name: deviceName,
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond), Liveness: &liveness})
+}
+
+// updateOperatingState disables an unreachable device, and enables it again
+// when it recovers if it was disabled by the monitor.
+func (m *Monitor) updateOperatingState(deviceName string, reachable bool) {
+	d, ok := cache.Devices().ForName(deviceName)
+	if !ok {
+		return
+	}
+
+	var state contract.OperatingState
+	switch {
+	case !reachable && d.OperatingState != contract.Disabled:
+		state = contract.Disabled
+	case reachable && common.DisabledBy(deviceName) == common.DisabledByLiveness:
+		state = contract.Enabled
+	default:
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := common.DeviceClient.UpdateOpStateByName(ctx, deviceName, string(state)); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Liveness - updating OperatingState of device %s to %s in Core Metadata failed: %v", deviceName, state, err))
+		return
+	}
+	if err := cache.Devices().UpdateOperatingState(deviceName, state); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Liveness - updating OperatingState of device %s in cache failed: %v", deviceName, err))
+	}
+
+	if state == contract.Disabled {
+		common.SetDisabledBy(deviceName, common.DisabledByLiveness)
+	} else {
+		common.ClearDisabledBy(deviceName)
+	}
+}
+
+// state returns the state of the device, the caller must hold the mutex.
+func (m *Monitor) state(deviceName string) *deviceState {
+	s, ok := m.states[deviceName]
+	if !ok {
+		s = &deviceState{}
+		m.states[deviceName] = s
+	}
+	return s
+}
+
+// Liveness reports the reachability of a device, ok is false if it hasn't
+// been determined yet.
+func (m *Monitor) Liveness(deviceName string) (dsModels.DeviceLiveness, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.states[deviceName]
+	if !ok || !s.known {
+		return dsModels.DeviceLiveness{Device: deviceName}, false
+	}
+	liveness := dsModels.DeviceLiveness{Device: deviceName, Reachable: s.reachable, Failures: s.failures}
+	if !s.lastSeen.IsZero() {
+		liveness.LastSeen = s.lastSeen.UnixNano() / int64(time.Millisecond)
+	}
+	return liveness, true
+}
@@ -0,0 +1,195 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package liveness
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/handler"
+	"github.com/edgexfoundry/device-sdk-go/internal/limiter"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// pinger fails the pings of the devices in unreachable, and blocks those of
+// the devices in hung.
+type pinger struct {
+	mutex       sync.Mutex
+	unreachable map[string]bool
+	hung        chan struct{}
+	pings       map[string]int
+}
+
+func (p *pinger) Ping(deviceName string, _ map[string]contract.ProtocolProperties) error {
+	p.mutex.Lock()
+	p.pings[deviceName]++
+	down := p.unreachable[deviceName]
+	p.mutex.Unlock()
+	if deviceName == "Hung-Device" {
+		<-p.hung
+	}
+	if down {
+		return errors.New("no route to host")
+	}
+	return nil
+}
+
+func (p *pinger) setUnreachable(deviceName string, unreachable bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.unreachable[deviceName] = unreachable
+}
+
+func initCache(t *testing.T, names ...string) {
+	common.LoggingClient = logger.NewMockClient()
+	common.CurrentConfig = &common.ConfigurationStruct{Device: common.DeviceInfo{UpdateLastConnected: true}}
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	cache.InitCache()
+	for _, name := range names {
+		_ = cache.Devices().RemoveByName(name)
+		require.NoError(t, cache.Devices().Add(contract.Device{Name: name, OperatingState: contract.Enabled}))
+		common.ClearDisabledBy(name)
+	}
+}
+
+func operatingState(name string) contract.OperatingState {
+	d, _ := cache.Devices().ForName(name)
+	return d.OperatingState
+}
+
+func TestMonitorReachability(t *testing.T) {
+	initCache(t, "Liveness-Device")
+	p := &pinger{unreachable: make(map[string]bool), pings: make(map[string]int)}
+	m := NewMonitor(p, Config{Interval: time.Minute, FailureThreshold: 2, UpdateOperatingState: true})
+	d, _ := cache.Devices().ForName("Liveness-Device")
+	notifications, cancel := common.Subscribe(10, dsModels.DeviceLivenessChanged)
+	defer cancel()
+
+	_, ok := m.Liveness("Liveness-Device")
+	assert.False(t, ok)
+
+	now := time.Now()
+	m.record(d, m.ping(context.Background(), d), now)
+	liveness, ok := m.Liveness("Liveness-Device")
+	require.True(t, ok)
+	assert.True(t, liveness.Reachable)
+	assert.Equal(t, now.UnixNano()/int64(time.Millisecond), liveness.LastSeen)
+
+	p.setUnreachable("Liveness-Device", true)
+	m.record(d, m.ping(context.Background(), d), now)
+	liveness, _ = m.Liveness("Liveness-Device")
+	assert.True(t, liveness.Reachable)
+	assert.Equal(t, 1, liveness.Failures)
+	assert.Equal(t, contract.OperatingState(contract.Enabled), operatingState("Liveness-Device"))
+
+	m.record(d, m.ping(context.Background(), d), now)
+	liveness, _ = m.Liveness("Liveness-Device")
+	assert.False(t, liveness.Reachable)
+	assert.Equal(t, contract.OperatingState(contract.Disabled), operatingState("Liveness-Device"))
+
+	p.setUnreachable("Liveness-Device", false)
+	m.record(d, m.ping(context.Background(), d), now)
+	liveness, _ = m.Liveness("Liveness-Device")
+	assert.True(t, liveness.Reachable)
+	assert.Equal(t, 0, liveness.Failures)
+	assert.Equal(t, contract.OperatingState(contract.Enabled), operatingState("Liveness-Device"))
+
+	// only the changes of reachability are notified
+	require.Len(t, notifications, 2)
+	n := <-notifications
+	assert.Equal(t, "Liveness-Device", n.Name)
+	require.NotNil(t, n.Liveness)
+	assert.False(t, n.Liveness.Reachable)
+	assert.Equal(t, 2, n.Liveness.Failures)
+	n = <-notifications
+	require.NotNil(t, n.Liveness)
+	assert.True(t, n.Liveness.Reachable)
+}
+
+func TestMonitorDisabledDeviceStaysDisabled(t *testing.T) {
+	initCache(t, "Liveness-Device")
+	require.NoError(t, cache.Devices().UpdateOperatingState("Liveness-Device", contract.Disabled))
+	p := &pinger{unreachable: map[string]bool{"Liveness-Device": true}, pings: make(map[string]int)}
+	m := NewMonitor(p, Config{Interval: time.Minute, UpdateOperatingState: true})
+	d, _ := cache.Devices().ForName("Liveness-Device")
+
+	m.record(d, m.ping(context.Background(), d), time.Now())
+	p.setUnreachable("Liveness-Device", false)
+	m.record(d, m.ping(context.Background(), d), time.Now())
+	// the monitor only enables the devices it disabled
+	assert.Equal(t, contract.OperatingState(contract.Disabled), operatingState("Liveness-Device"))
+}
+
+func TestMonitorTimeout(t *testing.T) {
+	initCache(t, "Hung-Device")
+	p := &pinger{unreachable: make(map[string]bool), hung: make(chan struct{}), pings: make(map[string]int)}
+	defer close(p.hung)
+	defer func() {
+		_ = cache.Devices().RemoveByName("Hung-Device")
+	}()
+	m := NewMonitor(p, Config{Interval: time.Minute, Timeout: 10 * time.Millisecond})
+
+	wg := &sync.WaitGroup{}
+	m.pingAll(context.Background(), wg)
+	wg.Wait()
+	liveness, ok := m.Liveness("Hung-Device")
+	require.True(t, ok)
+	assert.False(t, liveness.Reachable)
+
+	// the hung ping isn't repeated
+	m.pingAll(context.Background(), wg)
+	wg.Wait()
+	p.mutex.Lock()
+	assert.Equal(t, 1, p.pings["Hung-Device"])
+	p.mutex.Unlock()
+}
+
+func TestMonitorBusyDevice(t *testing.T) {
+	initCache(t, "Liveness-Device")
+	common.CurrentConfig.Device.Concurrency.MaxPerDevice = 1
+	common.DriverLimiter = limiter.New(5 * time.Millisecond)
+	defer func() {
+		common.DriverLimiter = nil
+	}()
+	p := &pinger{unreachable: make(map[string]bool), pings: make(map[string]int)}
+	m := NewMonitor(p, Config{Interval: time.Minute})
+	d, _ := cache.Devices().ForName("Liveness-Device")
+
+	// the ping waits for the command in progress, and is skipped when it
+	// can't get a slot in time
+	release, err := handler.AcquireDriver(context.Background(), &d)
+	require.NoError(t, err)
+	wg := &sync.WaitGroup{}
+	m.pingAll(context.Background(), wg)
+	wg.Wait()
+	_, ok := m.Liveness("Liveness-Device")
+	assert.False(t, ok)
+	p.mutex.Lock()
+	assert.Equal(t, 0, p.pings["Liveness-Device"])
+	p.mutex.Unlock()
+	release()
+
+	m.pingAll(context.Background(), wg)
+	wg.Wait()
+	liveness, ok := m.Liveness("Liveness-Device")
+	require.True(t, ok)
+	assert.True(t, liveness.Reachable)
+	assert.Equal(t, 0, common.InFlight.Count("Liveness-Device"))
+}
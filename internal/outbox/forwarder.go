@@ -0,0 +1,91 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SendFunc pushes a record to its destination. Returning a PermanentError
+// discards the record instead of retrying it.
+type SendFunc func(ctx context.Context, r Record) error
+
+// PermanentError wraps a delivery error which retrying cannot fix, e.g. the
+// event was rejected by the receiver as malformed.
+type PermanentError struct {
+	Err error
+}
+
+func (e PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Forward drains the outbox in order until ctx is cancelled. A record is only
+// removed once send succeeds, so a failing head blocks the records behind it
+// and events are always replayed in the order they were produced. Failed sends
+// are retried with an exponential backoff bounded by maxRetryInterval.
+func (o *Outbox) Forward(ctx context.Context, wg *sync.WaitGroup, send SendFunc, retryInterval time.Duration, maxRetryInterval time.Duration) {
+	defer wg.Done()
+
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+	if maxRetryInterval < retryInterval {
+		maxRetryInterval = retryInterval
+	}
+	backoff := retryInterval
+
+	for {
+		changed := o.waitChan()
+		r, ok := o.Peek()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				continue
+			}
+		}
+
+		err := send(ctx, r)
+		if err == nil {
+			o.Remove(r.Sequence)
+			o.mutex.Lock()
+			o.metrics.Forwarded++
+			o.mutex.Unlock()
+			backoff = retryInterval
+			continue
+		}
+
+		if _, ok := err.(PermanentError); ok {
+			o.lc.Error(fmt.Sprintf("outbox: event from %s was rejected and discarded: %v", r.Device, err))
+			o.Remove(r.Sequence)
+			o.mutex.Lock()
+			o.metrics.Rejected++
+			o.mutex.Unlock()
+			continue
+		}
+
+		o.lc.Warn(fmt.Sprintf("outbox: failed to forward event from %s, retrying in %v: %v", r.Device, backoff, err))
+		o.mutex.Lock()
+		o.metrics.Retries++
+		o.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxRetryInterval {
+			backoff = maxRetryInterval
+		}
+	}
+}
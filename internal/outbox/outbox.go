@@ -0,0 +1,356 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outbox implements a bounded, ordered, on-disk queue of encoded
+// events. Events are written to the outbox before delivery is attempted and
+// are only removed once the forwarder has pushed them successfully, so that
+// readings survive both Core Data outages and device service restarts.
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+const (
+	// DropOldest discards the oldest queued event to make room for a new one.
+	DropOldest = "DropOldest"
+	// DropNewest discards the event being enqueued when the outbox is full.
+	DropNewest = "DropNewest"
+	// Block makes the caller wait for free space, up to Config.BlockTimeout.
+	Block = "Block"
+
+	recordExt = ".evt"
+	tmpExt    = ".tmp"
+)
+
+// Config contains the settings used to open an Outbox.
+type Config struct {
+	// Path is the directory holding the queued events.
+	Path string
+	// MaxEvents is the maximum number of queued events, 0 means unlimited.
+	MaxEvents int
+	// MaxBytes is the maximum total size of queued events on disk, 0 means unlimited.
+	MaxBytes int64
+	// Retention is the maximum age of a queued event, 0 means events never expire.
+	Retention time.Duration
+	// OverflowPolicy is one of DropOldest, DropNewest or Block.
+	OverflowPolicy string
+	// BlockTimeout bounds how long Enqueue waits for space under the Block policy.
+	BlockTimeout time.Duration
+}
+
+// Record is a single encoded event held in the outbox.
+type Record struct {
+	// Sequence is the position of the record in the outbox; it is assigned by Enqueue.
+	Sequence uint64 `json:"-"`
+	// Device is the name of the device that produced the event.
+	Device string `json:"device"`
+	// ContentType is the content type used when pushing Payload.
+	ContentType string `json:"contentType"`
+	// Enqueued is the time, in nanoseconds, at which the record was queued.
+	Enqueued int64 `json:"enqueued"`
+	// Payload is the encoded event.
+	Payload []byte `json:"-"`
+}
+
+// Metrics is a snapshot of the outbox state and counters.
+type Metrics struct {
+	Depth     int
+	Bytes     int64
+	Enqueued  uint64
+	Forwarded uint64
+	Dropped   uint64
+	Expired   uint64
+	Rejected  uint64
+	Retries   uint64
+}
+
+type entry struct {
+	seq      uint64
+	size     int64
+	enqueued int64
+}
+
+// Outbox is a persistent FIFO queue of encoded events.
+type Outbox struct {
+	cfg     Config
+	lc      logger.LoggingClient
+	mutex   sync.Mutex
+	entries []entry
+	nextSeq uint64
+	bytes   int64
+	metrics Metrics
+	changed chan struct{}
+}
+
+// Open creates the outbox directory if needed and loads any records left
+// over from a previous run, preserving their order.
+func Open(cfg Config, lc logger.LoggingClient) (*Outbox, error) {
+	switch cfg.OverflowPolicy {
+	case "":
+		cfg.OverflowPolicy = DropOldest
+	case DropOldest, DropNewest, Block:
+	default:
+		return nil, fmt.Errorf("unknown outbox overflow policy %s", cfg.OverflowPolicy)
+	}
+
+	if err := os.MkdirAll(cfg.Path, 0750); err != nil {
+		return nil, fmt.Errorf("couldn't create outbox directory %s: %v", cfg.Path, err)
+	}
+
+	fileInfo, err := ioutil.ReadDir(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read outbox directory %s: %v", cfg.Path, err)
+	}
+
+	o := &Outbox{cfg: cfg, lc: lc, nextSeq: 1, changed: make(chan struct{})}
+	for _, file := range fileInfo {
+		name := file.Name()
+		if strings.HasSuffix(name, tmpExt) {
+			// a write was interrupted before the record was committed
+			_ = os.Remove(filepath.Join(cfg.Path, name))
+			continue
+		}
+		if !strings.HasSuffix(name, recordExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, recordExt), 10, 64)
+		if err != nil {
+			lc.Warn(fmt.Sprintf("outbox: ignoring unexpected file %s", name))
+			continue
+		}
+		o.entries = append(o.entries, entry{seq: seq, size: file.Size(), enqueued: file.ModTime().UnixNano()})
+		o.bytes += file.Size()
+		if seq >= o.nextSeq {
+			o.nextSeq = seq + 1
+		}
+	}
+	sort.Slice(o.entries, func(i, j int) bool { return o.entries[i].seq < o.entries[j].seq })
+
+	if len(o.entries) > 0 {
+		lc.Info(fmt.Sprintf("outbox: recovered %d queued events from %s", len(o.entries), cfg.Path))
+	}
+	return o, nil
+}
+
+// Enqueue persists the record at the tail of the outbox, applying the
+// overflow policy when the outbox is full.
+func (o *Outbox) Enqueue(r Record) error {
+	if r.Enqueued == 0 {
+		r.Enqueued = time.Now().UnixNano()
+	}
+	data, err := encode(r)
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(data))
+	if o.cfg.MaxBytes > 0 && size > o.cfg.MaxBytes {
+		o.mutex.Lock()
+		o.metrics.Dropped++
+		o.mutex.Unlock()
+		return fmt.Errorf("event from %s is %d bytes, larger than the outbox capacity of %d bytes", r.Device, size, o.cfg.MaxBytes)
+	}
+
+	var deadline <-chan time.Time
+	o.mutex.Lock()
+	for o.full(size) {
+		switch o.cfg.OverflowPolicy {
+		case DropNewest:
+			o.metrics.Dropped++
+			o.mutex.Unlock()
+			return fmt.Errorf("outbox is full, dropped newest event from %s", r.Device)
+		case DropOldest:
+			o.removeHead()
+			o.metrics.Dropped++
+			o.lc.Warn("outbox is full, dropped oldest queued event")
+		case Block:
+			if deadline == nil {
+				timer := time.NewTimer(o.cfg.BlockTimeout)
+				defer timer.Stop()
+				deadline = timer.C
+			}
+			changed := o.changed
+			o.mutex.Unlock()
+			select {
+			case <-changed:
+			case <-deadline:
+				o.mutex.Lock()
+				o.metrics.Dropped++
+				o.mutex.Unlock()
+				return fmt.Errorf("timed out waiting for space in the outbox, dropped event from %s", r.Device)
+			}
+			o.mutex.Lock()
+		}
+	}
+	defer o.mutex.Unlock()
+
+	r.Sequence = o.nextSeq
+	if err := o.write(r.Sequence, data); err != nil {
+		return err
+	}
+	o.nextSeq++
+	o.entries = append(o.entries, entry{seq: r.Sequence, size: size, enqueued: r.Enqueued})
+	o.bytes += size
+	o.metrics.Enqueued++
+	o.notify()
+	return nil
+}
+
+// Peek returns the record at the head of the outbox without removing it.
+// Expired records are discarded first.
+func (o *Outbox) Peek() (Record, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for len(o.entries) > 0 {
+		head := o.entries[0]
+		if o.cfg.Retention > 0 && time.Now().UnixNano()-head.enqueued > int64(o.cfg.Retention) {
+			o.removeHead()
+			o.metrics.Expired++
+			continue
+		}
+
+		r, err := o.read(head.seq)
+		if err != nil {
+			o.lc.Error(fmt.Sprintf("outbox: discarding unreadable event %d: %v", head.seq, err))
+			o.removeHead()
+			o.metrics.Dropped++
+			continue
+		}
+		return r, true
+	}
+	return Record{}, false
+}
+
+// Remove deletes the record with the given sequence from the head of the
+// outbox. It is a no-op if that record is no longer at the head.
+func (o *Outbox) Remove(seq uint64) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.entries) > 0 && o.entries[0].seq == seq {
+		o.removeHead()
+	}
+}
+
+// Metrics returns a snapshot of the outbox counters.
+func (o *Outbox) Metrics() Metrics {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	m := o.metrics
+	m.Depth = len(o.entries)
+	m.Bytes = o.bytes
+	return m
+}
+
+// Len returns the number of queued events.
+func (o *Outbox) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.entries)
+}
+
+// waitChan returns a channel which is closed the next time the outbox changes.
+func (o *Outbox) waitChan() <-chan struct{} {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.changed
+}
+
+func (o *Outbox) full(size int64) bool {
+	if o.cfg.MaxEvents > 0 && len(o.entries) >= o.cfg.MaxEvents {
+		return true
+	}
+	if o.cfg.MaxBytes > 0 && o.bytes+size > o.cfg.MaxBytes {
+		return true
+	}
+	return false
+}
+
+// removeHead must be called with the mutex held.
+func (o *Outbox) removeHead() {
+	head := o.entries[0]
+	if err := os.Remove(o.fileName(head.seq)); err != nil && !os.IsNotExist(err) {
+		o.lc.Error(fmt.Sprintf("outbox: couldn't remove event %d: %v", head.seq, err))
+	}
+	o.entries = o.entries[1:]
+	o.bytes -= head.size
+	o.notify()
+}
+
+// notify wakes up everything waiting on the outbox; it must be called with the mutex held.
+func (o *Outbox) notify() {
+	close(o.changed)
+	o.changed = make(chan struct{})
+}
+
+func (o *Outbox) fileName(seq uint64) string {
+	return filepath.Join(o.cfg.Path, fmt.Sprintf("%020d%s", seq, recordExt))
+}
+
+// encode serializes the record as a JSON header line followed by the raw payload.
+func encode(r Record) ([]byte, error) {
+	header, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteByte('\n')
+	buf.Write(r.Payload)
+	return buf.Bytes(), nil
+}
+
+// write stores an encoded record under a temporary name and renames it so
+// that a crash never leaves a partially written record behind.
+func (o *Outbox) write(seq uint64, data []byte) error {
+	name := o.fileName(seq)
+	if err := ioutil.WriteFile(name+tmpExt, data, 0640); err != nil {
+		return fmt.Errorf("couldn't write event to the outbox: %v", err)
+	}
+	if err := os.Rename(name+tmpExt, name); err != nil {
+		return fmt.Errorf("couldn't commit event to the outbox: %v", err)
+	}
+	return nil
+}
+
+func (o *Outbox) read(seq uint64) (Record, error) {
+	var r Record
+	data, err := ioutil.ReadFile(o.fileName(seq))
+	if err != nil {
+		return r, err
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	header, err := reader.ReadBytes('\n')
+	if err != nil {
+		return r, fmt.Errorf("missing record header")
+	}
+	if err = json.Unmarshal(header, &r); err != nil {
+		return r, err
+	}
+	r.Sequence = seq
+	r.Payload = data[len(header):]
+	return r, nil
+}
@@ -0,0 +1,159 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOutbox(t *testing.T, cfg Config) (*Outbox, func()) {
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+	cfg.Path = dir
+	o, err := Open(cfg, logger.MockLogger{})
+	require.NoError(t, err)
+	return o, func() { _ = os.RemoveAll(dir) }
+}
+
+func record(i int) Record {
+	return Record{Device: "device", ContentType: "application/json", Payload: []byte(fmt.Sprintf("event-%d", i))}
+}
+
+func TestOutbox_OrderAndPersistence(t *testing.T) {
+	o, cleanup := newTestOutbox(t, Config{})
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, o.Enqueue(record(i)))
+	}
+
+	// reopening the same directory recovers the queued events in order
+	reopened, err := Open(o.cfg, logger.MockLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, reopened.Len())
+	for i := 0; i < 3; i++ {
+		r, ok := reopened.Peek()
+		require.True(t, ok)
+		assert.Equal(t, fmt.Sprintf("event-%d", i), string(r.Payload))
+		assert.Equal(t, "application/json", r.ContentType)
+		reopened.Remove(r.Sequence)
+	}
+	_, ok := reopened.Peek()
+	assert.False(t, ok)
+
+	require.NoError(t, reopened.Enqueue(record(3)))
+	r, _ := reopened.Peek()
+	assert.Equal(t, uint64(4), r.Sequence, "sequence numbers must keep increasing after a restart")
+}
+
+func TestOutbox_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		expectError bool
+		expectHead  string
+	}{
+		{"DropOldest", DropOldest, false, "event-1"},
+		{"DropNewest", DropNewest, true, "event-0"},
+		{"Block", Block, true, "event-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, cleanup := newTestOutbox(t, Config{MaxEvents: 2, OverflowPolicy: tt.policy, BlockTimeout: 10 * time.Millisecond})
+			defer cleanup()
+
+			require.NoError(t, o.Enqueue(record(0)))
+			require.NoError(t, o.Enqueue(record(1)))
+			err := o.Enqueue(record(2))
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			r, _ := o.Peek()
+			assert.Equal(t, tt.expectHead, string(r.Payload))
+			assert.Equal(t, 2, o.Metrics().Depth)
+			assert.Equal(t, uint64(1), o.Metrics().Dropped)
+		})
+	}
+}
+
+func TestOutbox_BlockWaitsForSpace(t *testing.T) {
+	o, cleanup := newTestOutbox(t, Config{MaxEvents: 1, OverflowPolicy: Block, BlockTimeout: time.Second})
+	defer cleanup()
+
+	require.NoError(t, o.Enqueue(record(0)))
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		r, _ := o.Peek()
+		o.Remove(r.Sequence)
+	}()
+	assert.NoError(t, o.Enqueue(record(1)))
+}
+
+func TestOutbox_Retention(t *testing.T) {
+	o, cleanup := newTestOutbox(t, Config{Retention: time.Millisecond})
+	defer cleanup()
+
+	require.NoError(t, o.Enqueue(record(0)))
+	time.Sleep(5 * time.Millisecond)
+	_, ok := o.Peek()
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), o.Metrics().Expired)
+}
+
+func TestOutbox_ForwardRetriesInOrder(t *testing.T) {
+	o, cleanup := newTestOutbox(t, Config{})
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, o.Enqueue(record(i)))
+	}
+
+	var mutex sync.Mutex
+	var sent []string
+	attempts := 0
+	send := func(ctx context.Context, r Record) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("core data unreachable")
+		}
+		if string(r.Payload) == "event-1" {
+			return PermanentError{Err: fmt.Errorf("bad request")}
+		}
+		sent = append(sent, string(r.Payload))
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go o.Forward(ctx, &wg, send, time.Millisecond, time.Millisecond)
+
+	assert.Eventually(t, func() bool { return o.Len() == 0 }, time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, []string{"event-0", "event-2"}, sent)
+	m := o.Metrics()
+	assert.Equal(t, uint64(2), m.Forwarded)
+	assert.Equal(t, uint64(1), m.Rejected)
+	assert.Equal(t, uint64(1), m.Retries)
+}
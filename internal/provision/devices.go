@@ -9,9 +9,15 @@ package provision
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
+
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -36,6 +42,74 @@ func LoadDevices(deviceList []common.DeviceConfig) error {
 	return nil
 }
 
+// DeviceConfigs returns the devices of deviceList followed by the devices
+// defined in the files of devicesDir. A device of devicesDir whose name is
+// already used is skipped.
+func DeviceConfigs(deviceList []common.DeviceConfig, devicesDir string) []common.DeviceConfig {
+	if devicesDir == "" {
+		return deviceList
+	}
+
+	result := make([]common.DeviceConfig, len(deviceList))
+	copy(result, deviceList)
+	names := make(map[string]string, len(deviceList))
+	for _, dc := range deviceList {
+		names[dc.Name] = "DeviceList"
+	}
+
+	fileInfo, err := ioutil.ReadDir(devicesDir)
+	if err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("devices: couldn't read directory: %s; %v", devicesDir, err))
+		return result
+	}
+	for _, file := range fileInfo {
+		if file.IsDir() || !isDeviceFile(file.Name()) {
+			continue
+		}
+		fullPath := filepath.Join(devicesDir, file.Name())
+		dc, err := readDeviceFile(fullPath)
+		if err != nil {
+			common.LoggingClient.Error(err.Error())
+			continue
+		}
+		if source, ok := names[dc.Name]; ok {
+			common.LoggingClient.Error(fmt.Sprintf("devices: Device %s of %s is already defined in %s", dc.Name, fullPath, source))
+			continue
+		}
+		names[dc.Name] = fullPath
+		result = append(result, dc)
+	}
+	return result
+}
+
+func isDeviceFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".toml" || ext == ".json"
+}
+
+// readDeviceFile reads the device defined in a file, with the same fields as
+// a DeviceList entry.
+func readDeviceFile(fullPath string) (common.DeviceConfig, error) {
+	var dc common.DeviceConfig
+	data, err := ioutil.ReadFile(fullPath)
+	if err != nil {
+		return dc, fmt.Errorf("devices: couldn't read file: %s; %v", fullPath, err)
+	}
+
+	if strings.ToLower(filepath.Ext(fullPath)) == ".json" {
+		err = json.Unmarshal(data, &dc)
+	} else {
+		err = toml.Unmarshal(data, &dc)
+	}
+	if err != nil {
+		return dc, fmt.Errorf("invalid Device: %s; %v", fullPath, err)
+	}
+	if dc.Name == "" || dc.Profile == "" {
+		return dc, fmt.Errorf("invalid Device: %s; Name and Profile are required", fullPath)
+	}
+	return dc, nil
+}
+
 func createDevice(dc common.DeviceConfig) error {
 	prf, ok := cache.Profiles().ForName(dc.Profile)
 	if !ok {
@@ -0,0 +1,79 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package provision
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+const tomlDevice = `
+Name = "Sensor-01"
+Profile = "Sensor"
+Labels = [ "industrial" ]
+[Protocols]
+  [Protocols.modbus-tcp]
+    Address = "10.0.0.1"
+    Port = "502"
+[[AutoEvents]]
+  Frequency = "10s"
+  Resource = "Temperature"
+`
+
+const jsonDevice = `{
+  "name": "Sensor-02",
+  "profile": "Sensor",
+  "protocols": {"modbus-tcp": {"Address": "10.0.0.2", "Port": "502"}},
+  "autoEvents": [{"frequency": "5s", "onChange": true, "resource": "Temperature"}]
+}`
+
+func TestDeviceConfigs(t *testing.T) {
+	common.LoggingClient = logger.NewMockClient()
+
+	dir, err := ioutil.TempDir("", "devices")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sensor-01.toml"), []byte(tomlDevice), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sensor-02.json"), []byte(jsonDevice), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "duplicate.json"), []byte(`{"name": "Sensor-00", "profile": "Sensor"}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "noprofile.json"), []byte(`{"name": "Sensor-03"}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a device"), 0644))
+
+	deviceList := []common.DeviceConfig{{Name: "Sensor-00", Profile: "Sensor"}}
+	devices := DeviceConfigs(deviceList, dir)
+	require.Len(t, devices, 3)
+	assert.Equal(t, "Sensor-00", devices[0].Name)
+
+	byName := make(map[string]common.DeviceConfig)
+	for _, dc := range devices {
+		byName[dc.Name] = dc
+	}
+	fromTOML, ok := byName["Sensor-01"]
+	require.True(t, ok)
+	assert.Equal(t, "Sensor", fromTOML.Profile)
+	assert.Equal(t, []string{"industrial"}, fromTOML.Labels)
+	assert.Equal(t, "10.0.0.1", fromTOML.Protocols["modbus-tcp"]["Address"])
+	require.Len(t, fromTOML.AutoEvents, 1)
+	assert.Equal(t, "10s", fromTOML.AutoEvents[0].Frequency)
+
+	fromJSON, ok := byName["Sensor-02"]
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.2", fromJSON.Protocols["modbus-tcp"]["Address"])
+	require.Len(t, fromJSON.AutoEvents, 1)
+	assert.True(t, fromJSON.AutoEvents[0].OnChange)
+
+	assert.Equal(t, deviceList, DeviceConfigs(deviceList, ""))
+}
@@ -10,21 +10,16 @@ package provision
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"strings"
 
 	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/validator"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
-	"gopkg.in/yaml.v2"
-)
-
-const (
-	yamlExt = ".yaml"
-	ymlExt  = ".yml"
 )
 
 func LoadProfiles(path string) error {
@@ -54,49 +49,90 @@ func LoadProfiles(path string) error {
 	}
 
 	for _, file := range fileInfo {
-		var profile contract.DeviceProfile
-
 		fName := file.Name()
-		lfName := strings.ToLower(fName)
-		if strings.HasSuffix(lfName, yamlExt) || strings.HasSuffix(lfName, ymlExt) {
-			fullPath := absPath + "/" + fName
-			yamlFile, err := ioutil.ReadFile(fullPath)
-			if err != nil {
-				common.LoggingClient.Error(fmt.Sprintf("profiles: couldn't read file: %s; %v", fullPath, err))
-				continue
-			}
+		if !validator.IsProfileFile(fName) {
+			continue
+		}
+		fullPath := absPath + "/" + fName
+		profile, err := readProfileFile(fullPath)
+		if err == errNotProfile {
+			common.LoggingClient.Debug(fmt.Sprintf("profiles: skipping %s, it doesn't define a Device Profile", fullPath))
+			continue
+		} else if err != nil {
+			common.LoggingClient.Error(err.Error())
+			continue
+		}
+
+		// if profile already exists in metadata, skip it
+		if p, ok := pMap[profile.Name]; ok {
+			_ = cache.Profiles().Add(p)
+			continue
+		}
 
-			err = yaml.Unmarshal(yamlFile, &profile)
-			if err != nil {
-				common.LoggingClient.Error(fmt.Sprintf("invalid Device Profile: %s; %v", fullPath, err))
-				continue
+		// add profile to metadata
+		if err = addProfile(profile); err != nil {
+			if _, ok := err.(idFormatError); ok {
+				return err
 			}
+			common.LoggingClient.Error(fmt.Sprintf("Add Device Profile: %s to Core Metadata failed: %v", fullPath, err))
+		}
+	}
+	return nil
+}
 
-			// TODO: this section will be removed after the deprecated fields are truly removed
-			handleDeprecatedFields(&profile)
+// errNotProfile is returned by readProfileFile for the files of the profiles
+// directory which define something else, e.g. a provision watcher.
+var errNotProfile = errors.New("not a Device Profile")
 
-			// if profile already exists in metadata, skip it
-			if p, ok := pMap[profile.Name]; ok {
-				_ = cache.Profiles().Add(p)
-				continue
-			}
+// readProfileFile reads the device profile defined in a file, and logs the
+// issues found by its validation as warnings.
+func readProfileFile(fullPath string) (contract.DeviceProfile, error) {
+	var profile contract.DeviceProfile
+	data, err := ioutil.ReadFile(fullPath)
+	if err != nil {
+		return profile, fmt.Errorf("profiles: couldn't read file: %s; %v", fullPath, err)
+	}
 
-			// add profile to metadata
-			ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
-			id, err := common.DeviceProfileClient.Add(ctx, &profile)
-			if err != nil {
-				common.LoggingClient.Error(fmt.Sprintf("Add Device Profile: %s to Core Metadata failed: %v", fullPath, err))
-				continue
-			}
-			if err = common.VerifyIdFormat(id, "Device Profile"); err != nil {
-				return err
-			}
+	profile, err = validator.ParseFile(fullPath, data)
+	if err != nil {
+		return profile, fmt.Errorf("invalid Device Profile: %s; %v", fullPath, err)
+	}
+	if !validator.IsProfile(profile) {
+		return profile, errNotProfile
+	}
 
-			profile.Id = id
-			cache.Profiles().Add(profile)
-			CreateDescriptorsFromProfile(&profile)
-		}
+	// TODO: this section will be removed after the deprecated fields are truly removed
+	handleDeprecatedFields(&profile)
+
+	// the issues are only reported, as the profiles loaded regardless of
+	// them before the validator was introduced
+	report := validator.Validate(profile)
+	if !report.Valid {
+		report.Source = fullPath
+		common.LoggingClient.Warn(fmt.Sprintf("Device Profile validation: %s", report.String()))
 	}
+	return profile, nil
+}
+
+// idFormatError is returned by addProfile when metadata returned an invalid id.
+type idFormatError struct {
+	error
+}
+
+// addProfile adds a device profile to metadata and to the cache.
+func addProfile(profile contract.DeviceProfile) error {
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	id, err := common.DeviceProfileClient.Add(ctx, &profile)
+	if err != nil {
+		return err
+	}
+	if err = common.VerifyIdFormat(id, "Device Profile"); err != nil {
+		return idFormatError{err}
+	}
+
+	profile.Id = id
+	cache.Profiles().Add(profile)
+	CreateDescriptorsFromProfile(&profile)
 	return nil
 }
 
@@ -0,0 +1,381 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package provision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/validator"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher polls the profiles directory, the devices directory and the
+// configuration file every interval, comparing the modification time and size
+// of the files as filesystem notifications aren't used, and applies the profiles and devices which have been
+// added, changed or removed since the service started, so that commissioning
+// doesn't require restarts. Devices are created, updated and removed through
+// Core Metadata, which calls back the service to update the cache, the driver
+// and the autoevents.
+type Watcher struct {
+	profilesDir string
+	devicesDir  string
+	configFile  string
+	// profileFiles maps the profile files to their last known state
+	profileFiles map[string]fileState
+	// profileNames maps the profile files to the name of the profile they define
+	profileNames map[string]string
+	// deviceFiles maps the device files to their last known state
+	deviceFiles map[string]fileState
+	configState fileState
+	// configDevices is the DeviceList of the configuration file
+	configDevices []common.DeviceConfig
+	// deviceList is the DeviceList merged with the devices of devicesDir
+	deviceList []common.DeviceConfig
+}
+
+// NewWatcher creates a Watcher for the profiles in profilesDir, the devices in
+// devicesDir and the DeviceList of configFile, any of which may be empty.
+// configDevices is the DeviceList of configFile and loadedDevices the devices
+// loaded at startup, configDevices merged with the devices of devicesDir, so
+// that the files aren't read and their errors logged twice.
+func NewWatcher(profilesDir string, devicesDir string, configFile string, configDevices []common.DeviceConfig, loadedDevices []common.DeviceConfig) *Watcher {
+	w := &Watcher{
+		configFile:    configFile,
+		profileNames:  make(map[string]string),
+		configDevices: configDevices,
+		deviceList:    loadedDevices,
+	}
+	if profilesDir != "" {
+		if absPath, err := filepath.Abs(profilesDir); err == nil {
+			w.profilesDir = absPath
+		}
+	}
+	if devicesDir != "" {
+		if absPath, err := filepath.Abs(devicesDir); err == nil {
+			w.devicesDir = absPath
+		}
+	}
+
+	// take the initial snapshot, the files have just been loaded
+	w.profileFiles = scanFiles(w.profilesDir, validator.IsProfileFile)
+	for path := range w.profileFiles {
+		if profile, err := readProfileFile(path); err == nil {
+			w.profileNames[path] = profile.Name
+		}
+	}
+	w.deviceFiles = scanFiles(w.devicesDir, isDeviceFile)
+	if configFile != "" {
+		w.configState, _ = stat(configFile)
+	}
+	return w
+}
+
+// Run polls for changes every interval until ctx is cancelled. The caller must
+// Add to wg beforehand.
+func (w *Watcher) Run(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Poll()
+		}
+	}
+}
+
+// Poll detects and applies the changes since the previous poll.
+func (w *Watcher) Poll() {
+	w.pollProfiles()
+	w.pollDeviceList()
+}
+
+func (w *Watcher) pollProfiles() {
+	if w.profilesDir == "" {
+		return
+	}
+
+	current := scanFiles(w.profilesDir, validator.IsProfileFile)
+	paths := make([]string, 0, len(current))
+	for path := range current {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		state := current[path]
+		if previous, ok := w.profileFiles[path]; ok && previous == state {
+			continue
+		}
+		w.profileFiles[path] = state
+
+		profile, err := readProfileFile(path)
+		if err == errNotProfile {
+			continue
+		} else if err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Watcher: %v", err))
+			continue
+		}
+		if previousName, ok := w.profileNames[path]; ok && previousName != profile.Name {
+			w.removeProfile(previousName)
+		}
+		w.profileNames[path] = profile.Name
+		applyProfile(profile)
+	}
+
+	for path := range w.profileFiles {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		delete(w.profileFiles, path)
+		if name, ok := w.profileNames[path]; ok {
+			delete(w.profileNames, path)
+			w.removeProfile(name)
+		}
+	}
+}
+
+// scanFiles returns the state of the files of dir whose name is matched.
+func scanFiles(dir string, match func(name string) bool) map[string]fileState {
+	result := make(map[string]fileState)
+	if dir == "" {
+		return result
+	}
+	fileInfo, err := ioutil.ReadDir(dir)
+	if err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Watcher: couldn't read directory: %s; %v", dir, err))
+		return result
+	}
+	for _, file := range fileInfo {
+		if file.IsDir() || !match(file.Name()) {
+			continue
+		}
+		result[filepath.Join(dir, file.Name())] = fileState{modTime: file.ModTime(), size: file.Size()}
+	}
+	return result
+}
+
+// applyProfile adds a new profile, or updates a cached profile which differs
+// from the file, in metadata and in the cache, then refreshes the devices using it.
+func applyProfile(profile contract.DeviceProfile) {
+	cached, ok := cache.Profiles().ForName(profile.Name)
+	if !ok {
+		if err := addProfile(profile); err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Watcher: adding Device Profile %s failed: %v", profile.Name, err))
+			return
+		}
+		common.LoggingClient.Info(fmt.Sprintf("Watcher: added Device Profile %s", profile.Name))
+		return
+	}
+
+	profile.Id = cached.Id
+	profile.Timestamps = cached.Timestamps
+	if sameProfile(profile, cached) {
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := common.DeviceProfileClient.Update(ctx, profile); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Watcher: updating Device Profile %s in Core Metadata failed: %v", profile.Name, err))
+		return
+	}
+	if err := cache.Profiles().Update(profile); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Watcher: updating Device Profile %s in cache failed: %v", profile.Name, err))
+		return
+	}
+	CreateDescriptorsFromProfile(&profile)
+	common.LoggingClient.Info(fmt.Sprintf("Watcher: updated Device Profile %s", profile.Name))
+
+	for _, d := range cache.Devices().All() {
+		if d.Profile.Name != profile.Name {
+			continue
+		}
+		d.Profile = profile
+		_ = cache.Devices().Update(d)
+		common.WaitForOperations(d.Name)
+		if err := common.Driver.UpdateDevice(d.Name, d.Protocols, d.AdminState); err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Watcher: failed to update device %s in protocoldriver: %v", d.Name, err))
+		}
+		autoevent.GetManager().RestartForDevice(d.Name)
+	}
+}
+
+// removeProfile deletes a profile whose file has been removed, if enabled by
+// HotReload DeleteRemoved. Core Metadata refuses to delete profiles which are
+// still used by devices.
+func (w *Watcher) removeProfile(name string) {
+	for _, other := range w.profileNames {
+		if other == name {
+			// still defined by another file
+			return
+		}
+	}
+	if _, ok := cache.Profiles().ForName(name); !ok {
+		return
+	}
+	if !common.CurrentConfig.Device.HotReload.DeleteRemoved {
+		common.LoggingClient.Info(fmt.Sprintf("Watcher: Device Profile %s is no longer defined by a file, it is kept in Core Metadata", name))
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := common.DeviceProfileClient.DeleteByName(ctx, name); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Watcher: removing Device Profile %s from Core Metadata failed: %v", name, err))
+		return
+	}
+	_ = cache.Profiles().RemoveByName(name)
+	common.LoggingClient.Info(fmt.Sprintf("Watcher: removed Device Profile %s", name))
+}
+
+func sameProfile(a contract.DeviceProfile, b contract.DeviceProfile) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+func (w *Watcher) pollDeviceList() {
+	changed := false
+	if w.configFile != "" {
+		if state, err := stat(w.configFile); err == nil && state != w.configState {
+			w.configState = state
+			var config struct {
+				DeviceList []common.DeviceConfig
+			}
+			if _, err := toml.DecodeFile(w.configFile, &config); err != nil {
+				common.LoggingClient.Error(fmt.Sprintf("Watcher: couldn't parse %s: %v", w.configFile, err))
+			} else {
+				w.configDevices = config.DeviceList
+				changed = true
+			}
+		}
+	}
+	if w.devicesDir != "" {
+		if files := scanFiles(w.devicesDir, isDeviceFile); !sameFiles(files, w.deviceFiles) {
+			w.deviceFiles = files
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	deviceList := DeviceConfigs(w.configDevices, w.devicesDir)
+	names := make(map[string]bool, len(deviceList))
+	for _, dc := range deviceList {
+		names[dc.Name] = true
+		applyDeviceConfig(dc)
+	}
+	for _, dc := range w.deviceList {
+		if names[dc.Name] {
+			continue
+		}
+		if _, ok := cache.Devices().ForName(dc.Name); !ok {
+			continue
+		}
+		if !common.CurrentConfig.Device.HotReload.DeleteRemoved {
+			common.LoggingClient.Info(fmt.Sprintf("Watcher: Device %s is no longer in the DeviceList, it is kept in Core Metadata", dc.Name))
+			continue
+		}
+		ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+		if err := common.DeviceClient.DeleteByName(ctx, dc.Name); err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Watcher: removing Device %s from Core Metadata failed: %v", dc.Name, err))
+			continue
+		}
+		common.LoggingClient.Info(fmt.Sprintf("Watcher: removed Device %s", dc.Name))
+	}
+	w.deviceList = deviceList
+}
+
+func sameFiles(a map[string]fileState, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || other != state {
+			return false
+		}
+	}
+	return true
+}
+
+// applyDeviceConfig creates the device, or updates it in metadata if the
+// cached device differs from its DeviceList entry.
+func applyDeviceConfig(dc common.DeviceConfig) {
+	device, ok := cache.Devices().ForName(dc.Name)
+	if !ok {
+		if err := createDevice(dc); err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("Watcher: creating Device %s failed: %v", dc.Name, err))
+			return
+		}
+		common.LoggingClient.Info(fmt.Sprintf("Watcher: added Device %s", dc.Name))
+		return
+	}
+
+	if device.Profile.Name == dc.Profile &&
+		device.Description == dc.Description &&
+		common.CompareStrings(device.Labels, dc.Labels) &&
+		reflect.DeepEqual(device.Protocols, dc.Protocols) &&
+		reflect.DeepEqual(normalizeAutoEvents(device.AutoEvents), normalizeAutoEvents(dc.AutoEvents)) {
+		return
+	}
+
+	profile, ok := cache.Profiles().ForName(dc.Profile)
+	if !ok {
+		common.LoggingClient.Error(fmt.Sprintf("Watcher: Device Profile %s doesn't exist for Device %s", dc.Profile, dc.Name))
+		return
+	}
+	device.Profile = profile
+	device.Description = dc.Description
+	device.Labels = dc.Labels
+	device.Protocols = dc.Protocols
+	device.AutoEvents = dc.AutoEvents
+
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := common.DeviceClient.Update(ctx, device); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("Watcher: updating Device %s in Core Metadata failed: %v", dc.Name, err))
+		return
+	}
+	common.LoggingClient.Info(fmt.Sprintf("Watcher: updated Device %s", dc.Name))
+}
+
+func normalizeAutoEvents(aes []contract.AutoEvent) []contract.AutoEvent {
+	if len(aes) == 0 {
+		return nil
+	}
+	return aes
+}
+
+func stat(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
@@ -0,0 +1,143 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package provision
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+)
+
+const watcherProfile = `name: "Watcher-Profile"
+deviceResources:
+  -
+    name: "Temperature"
+    properties:
+      value:
+        { type: "Int16", readWrite: "R" }
+      units:
+        { type: "String", readWrite: "R", defaultValue: "%s" }
+`
+
+type recordingProfileClient struct {
+	mock.DeviceProfileClientMock
+	added   []string
+	updated []string
+	deleted []string
+}
+
+func (c *recordingProfileClient) Add(_ context.Context, dp *contract.DeviceProfile) (string, error) {
+	c.added = append(c.added, dp.Name)
+	return "5b977c62f37ba10e36673900", nil
+}
+
+func (c *recordingProfileClient) Update(_ context.Context, dp contract.DeviceProfile) error {
+	c.updated = append(c.updated, dp.Name)
+	return nil
+}
+
+func (c *recordingProfileClient) DeleteByName(_ context.Context, name string) error {
+	c.deleted = append(c.deleted, name)
+	return nil
+}
+
+type metadataGeneralClient struct{}
+
+func (metadataGeneralClient) FetchConfiguration(_ context.Context) (string, error) {
+	return `{"Writable":{"EnableValueDescriptorManagement":true}}`, nil
+}
+
+func (metadataGeneralClient) FetchMetrics(_ context.Context) (string, error) {
+	return "{}", nil
+}
+
+func writeProfile(t *testing.T, path string, units string) {
+	content := []byte(fmt.Sprintf(watcherProfile, units))
+	require.NoError(t, ioutil.WriteFile(path, content, 0644))
+}
+
+func TestWatcherProfiles(t *testing.T) {
+	common.LoggingClient = logger.NewMockClient()
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	common.MetadataGeneralClient = metadataGeneralClient{}
+	common.CurrentConfig = &common.ConfigurationStruct{Device: common.DeviceInfo{MaxCmdOps: 128}}
+	cache.InitCache()
+	profiles := &recordingProfileClient{}
+	common.DeviceProfileClient = profiles
+
+	dir, err := ioutil.TempDir("", "profiles")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w := NewWatcher(dir, "", "", nil, nil)
+	path := filepath.Join(dir, "watcher.yaml")
+
+	writeProfile(t, path, "C")
+	w.Poll()
+	assert.Equal(t, []string{"Watcher-Profile"}, profiles.added)
+	_, ok := cache.Profiles().ForName("Watcher-Profile")
+	assert.True(t, ok)
+
+	// polling without changes is a no-op
+	w.Poll()
+	assert.Len(t, profiles.added, 1)
+	assert.Empty(t, profiles.updated)
+
+	writeProfile(t, path, "Kelvin")
+	w.Poll()
+	assert.Equal(t, []string{"Watcher-Profile"}, profiles.updated)
+	dr, ok := cache.Profiles().DeviceResource("Watcher-Profile", "Temperature")
+	require.True(t, ok)
+	assert.Equal(t, "Kelvin", dr.Properties.Units.DefaultValue)
+
+	// removed profiles are kept in metadata unless DeleteRemoved is set
+	require.NoError(t, os.Remove(path))
+	w.Poll()
+	assert.Empty(t, profiles.deleted)
+	_, ok = cache.Profiles().ForName("Watcher-Profile")
+	assert.True(t, ok)
+
+	common.CurrentConfig.Device.HotReload.DeleteRemoved = true
+	writeProfile(t, path, "Kelvin")
+	w.Poll()
+	require.NoError(t, os.Remove(path))
+	w.Poll()
+	assert.Equal(t, []string{"Watcher-Profile"}, profiles.deleted)
+	_, ok = cache.Profiles().ForName("Watcher-Profile")
+	assert.False(t, ok)
+}
+
+func TestNewWatcherLoadedDevices(t *testing.T) {
+	common.LoggingClient = logger.NewMockClient()
+
+	dir, err := ioutil.TempDir("", "devices")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sensor-01.toml"), []byte(tomlDevice), 0644))
+
+	configDevices := []common.DeviceConfig{{Name: "Sensor-00", Profile: "Sensor"}}
+	loaded := DeviceConfigs(configDevices, dir)
+	w := NewWatcher("", dir, "", configDevices, loaded)
+	// the devices loaded at startup are reused rather than read again
+	assert.Equal(t, loaded, w.deviceList)
+	assert.Equal(t, configDevices, w.configDevices)
+	assert.Len(t, w.deviceFiles, 1)
+}
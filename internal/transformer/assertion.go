@@ -0,0 +1,302 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2019-2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// Policies applied when a reading fails the assertion of its device resource.
+const (
+	// AssertionDisable marks the reading and disables the device after
+	// FailureThreshold consecutive failures, which is the default.
+	AssertionDisable = "Disable"
+	// AssertionDrop removes the reading from the event.
+	AssertionDrop = "Drop"
+	// AssertionMark marks the reading without disabling the device.
+	AssertionMark = "Mark"
+)
+
+// Prefixes of the assertions other than the equality to a string.
+const (
+	rangeAssertion = "range:"
+	regexAssertion = "regex:"
+	inAssertion    = "in:"
+	eqAssertion    = "eq:"
+)
+
+// AssertionConfig holds the parsed Device.Assertion settings, which the
+// device resource attributes can override.
+type AssertionConfig struct {
+	Policy           string
+	FailureThreshold int
+	// ReenableAfter is the time after which a device disabled because of an
+	// assertion failure is enabled again, 0 means never.
+	ReenableAfter time.Duration
+}
+
+var (
+	assertionConfig AssertionConfig
+	// assertionFailures counts the consecutive failures by device and resource
+	assertionFailures = make(map[string]int)
+	// reenableTimers holds the timers enabling the devices disabled because
+	// of assertion failures
+	reenableTimers = make(map[string]*time.Timer)
+	regexps        = make(map[string]*regexp.Regexp)
+	assertionMutex sync.Mutex
+)
+
+// SetAssertionConfig sets the default assertion failure policy.
+func SetAssertionConfig(config AssertionConfig) {
+	assertionMutex.Lock()
+	defer assertionMutex.Unlock()
+	assertionConfig = config
+}
+
+// ParseAssertionPolicy returns the canonical name of a policy, an empty
+// policy is the default one.
+func ParseAssertionPolicy(policy string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case "", strings.ToLower(AssertionDisable):
+		return AssertionDisable, nil
+	case strings.ToLower(AssertionDrop):
+		return AssertionDrop, nil
+	case strings.ToLower(AssertionMark):
+		return AssertionMark, nil
+	}
+	return "", fmt.Errorf("unknown assertion policy %q", policy)
+}
+
+// CheckAssertion checks the value against the assertion of the device resource,
+// and applies the assertion policy of the resource when it fails. It returns
+// the failure, and whether the reading must be dropped.
+func CheckAssertion(cv *dsModels.CommandValue, dr contract.DeviceResource, device *contract.Device) (bool, error) {
+	assertion := dr.Properties.Value.Assertion
+	if assertion == "" {
+		return false, nil
+	}
+
+	key := device.Name + "/" + dr.Name
+	passed, err := assertionPassed(cv.ValueToString(), assertion)
+	if err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("invalid assertion (%s) of device resource %s: %v", assertion, dr.Name, err))
+		return false, nil
+	}
+	if passed {
+		assertionMutex.Lock()
+		delete(assertionFailures, key)
+		assertionMutex.Unlock()
+		return false, nil
+	}
+
+	policy, threshold := resourceAssertionPolicy(dr)
+	assertionMutex.Lock()
+	assertionFailures[key]++
+	failures := assertionFailures[key]
+	assertionMutex.Unlock()
+
+	msg := fmt.Sprintf("assertion (%s) failed with value: %s", assertion, cv.ValueToString())
+	common.LoggingClient.Error(msg)
+	switch policy {
+	case AssertionDrop:
+		return true, fmt.Errorf(msg)
+	case AssertionDisable:
+		if failures >= threshold {
+			disableForAssertion(device)
+		}
+	}
+	return false, fmt.Errorf(msg)
+}
+
+// resourceAssertionPolicy returns the policy and failure threshold of a device
+// resource, which default to the configured ones.
+func resourceAssertionPolicy(dr contract.DeviceResource) (string, int) {
+	assertionMutex.Lock()
+	config := assertionConfig
+	assertionMutex.Unlock()
+
+	policy, err := ParseAssertionPolicy(config.Policy)
+	if err != nil {
+		policy = AssertionDisable
+	}
+	if value, ok := dr.Attributes[common.AssertionPolicyAttribute]; ok {
+		if p, err := ParseAssertionPolicy(value); err != nil {
+			common.LoggingClient.Error(fmt.Sprintf("device resource %s: %v", dr.Name, err))
+		} else {
+			policy = p
+		}
+	}
+
+	threshold := config.FailureThreshold
+	if value, ok := dr.Attributes[common.AssertionFailuresAttribute]; ok {
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			common.LoggingClient.Error(fmt.Sprintf("device resource %s: invalid assertion failure threshold %q", dr.Name, value))
+		} else {
+			threshold = n
+		}
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+	return policy, threshold
+}
+
+// assertionPassed evaluates an assertion, which is one of:
+//
+//	range:min,max  a numeric value within the inclusive range, either bound may be empty
+//	regex:expr     a value matching the regular expression
+//	in:a,b,c       a value in the comma separated list
+//	eq:value       a value equal to the string
+//	value          a value equal to the string
+func assertionPassed(value string, assertion string) (bool, error) {
+	switch {
+	case strings.HasPrefix(assertion, rangeAssertion):
+		return inRange(value, strings.TrimPrefix(assertion, rangeAssertion))
+	case strings.HasPrefix(assertion, regexAssertion):
+		re, err := compileRegexp(strings.TrimPrefix(assertion, regexAssertion))
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(value), nil
+	case strings.HasPrefix(assertion, inAssertion):
+		for _, s := range strings.Split(strings.TrimPrefix(assertion, inAssertion), ",") {
+			if strings.TrimSpace(s) == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case strings.HasPrefix(assertion, eqAssertion):
+		return value == strings.TrimPrefix(assertion, eqAssertion), nil
+	}
+	return value == assertion, nil
+}
+
+func inRange(value string, bounds string) (bool, error) {
+	parts := strings.Split(bounds, ",")
+	if len(parts) != 2 {
+		return false, fmt.Errorf("range %q must be min,max", bounds)
+	}
+	limits := make([]*float64, 2)
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		limit, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid range bound %q", part)
+		}
+		limits[i] = &limit
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		// a value which isn't a number can't be in the range
+		return false, nil
+	}
+	return (limits[0] == nil || v >= *limits[0]) && (limits[1] == nil || v <= *limits[1]), nil
+}
+
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	assertionMutex.Lock()
+	defer assertionMutex.Unlock()
+
+	if re, ok := regexps[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexps[expr] = re
+	return re, nil
+}
+
+// disableForAssertion sets the OperatingState of the device to DISABLED, and
+// schedules its re-enabling if configured.
+func disableForAssertion(device *contract.Device) {
+	if device.OperatingState == contract.Disabled {
+		return
+	}
+	device.OperatingState = contract.Disabled
+	_ = cache.Devices().UpdateOperatingState(device.Name, contract.Disabled)
+	common.SetDisabledBy(device.Name, common.DisabledByAssertion)
+	// Core Metadata is updated off the goroutine of the reading, as it calls
+	// back the service with the updated device
+	go disableInMetadata(common.DeviceClient, device.Name)
+}
+
+// disableInMetadata sets the OperatingState of a device disabled because of
+// an assertion failure to DISABLED in Core Metadata. The cache is rolled back
+// if it fails, as the next update from Core Metadata would revert it anyway.
+func disableInMetadata(client metadata.DeviceClient, deviceName string) {
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := client.UpdateOpStateByName(ctx, deviceName, contract.Disabled); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("disabling Device %s after an assertion failure in Core Metadata failed: %v", deviceName, err))
+		if common.DisabledBy(deviceName) == common.DisabledByAssertion {
+			common.ClearDisabledBy(deviceName)
+			if err := cache.Devices().UpdateOperatingState(deviceName, contract.Enabled); err != nil {
+				common.LoggingClient.Error(fmt.Sprintf("restoring OperatingState of Device %s in cache failed: %v", deviceName, err))
+			}
+		}
+		return
+	}
+
+	assertionMutex.Lock()
+	defer assertionMutex.Unlock()
+	if assertionConfig.ReenableAfter <= 0 {
+		return
+	}
+	if _, ok := reenableTimers[deviceName]; ok {
+		return
+	}
+	common.LoggingClient.Info(fmt.Sprintf("Device %s disabled after an assertion failure will be enabled again in %v", deviceName, assertionConfig.ReenableAfter))
+	reenableTimers[deviceName] = time.AfterFunc(assertionConfig.ReenableAfter, func() {
+		reenableDevice(deviceName)
+	})
+}
+
+// reenableDevice enables a device disabled because of an assertion failure,
+// unless its OperatingState has been changed since, by an operator or by
+// another subsystem of the SDK.
+func reenableDevice(deviceName string) {
+	assertionMutex.Lock()
+	delete(reenableTimers, deviceName)
+	for key := range assertionFailures {
+		if strings.HasPrefix(key, deviceName+"/") {
+			delete(assertionFailures, key)
+		}
+	}
+	assertionMutex.Unlock()
+
+	device, ok := cache.Devices().ForName(deviceName)
+	if !ok || device.OperatingState != contract.Disabled || common.DisabledBy(deviceName) != common.DisabledByAssertion {
+		return
+	}
+	ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
+	if err := common.DeviceClient.UpdateOpStateByName(ctx, deviceName, contract.Enabled); err != nil {
+		common.LoggingClient.Error(fmt.Sprintf("enabling Device %s disabled after an assertion failure failed: %v", deviceName, err))
+		return
+	}
+	common.ClearDisabledBy(deviceName)
+	_ = cache.Devices().UpdateOperatingState(deviceName, contract.Enabled)
+	common.LoggingClient.Info(fmt.Sprintf("Device %s disabled after an assertion failure enabled again", deviceName))
+}
@@ -0,0 +1,160 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/mock"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestAssertionPassed(t *testing.T) {
+	tests := []struct {
+		value     string
+		assertion string
+		passed    bool
+	}{
+		{"true", "true", true},
+		{"false", "true", false},
+		{"25.5", "range:10,30", true},
+		{"30", "range:10,30", true},
+		{"30.1", "range:10,30", false},
+		{"-100", "range:,0", true},
+		{"NaN?", "range:0,", false},
+		{"OK-12", "regex:^OK-[0-9]+$", true},
+		{"KO-12", "regex:^OK-[0-9]+$", false},
+		{"idle", "in:idle, running", true},
+		{"running", "in:idle, running", true},
+		{"stopped", "in:idle, running", false},
+		{"in:x", "eq:in:x", true},
+	}
+	for _, tt := range tests {
+		passed, err := assertionPassed(tt.value, tt.assertion)
+		require.NoError(t, err, tt.assertion)
+		assert.Equal(t, tt.passed, passed, "%s %s", tt.value, tt.assertion)
+	}
+
+	_, err := assertionPassed("1", "range:1")
+	assert.Error(t, err)
+	_, err = assertionPassed("1", "regex:(")
+	assert.Error(t, err)
+}
+
+func initAssertionCache(t *testing.T) contract.Device {
+	common.DeviceClient = &mock.DeviceClientMock{}
+	common.ValueDescriptorClient = &mock.ValueDescriptorMock{}
+	common.ProvisionWatcherClient = &mock.ProvisionWatcherClientMock{}
+	cache.InitCache()
+	device := contract.Device{Name: "Assertion-Device", OperatingState: contract.Enabled}
+	_ = cache.Devices().RemoveByName(device.Name)
+	require.NoError(t, cache.Devices().Add(device))
+	common.ClearDisabledBy(device.Name)
+	return device
+}
+
+// failingDeviceClient fails the updates of the OperatingState.
+type failingDeviceClient struct {
+	mock.DeviceClientMock
+}
+
+func (dc *failingDeviceClient) UpdateOpStateByName(_ context.Context, _ string, _ string) error {
+	return errors.New("metadata unavailable")
+}
+
+func TestCheckAssertionPolicies(t *testing.T) {
+	device := initAssertionCache(t)
+	cv, _ := dsModels.NewInt32Value("Level", 0, 99)
+	dr := contract.DeviceResource{Name: "Level", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Assertion: "range:0,50"}}}
+
+	dr.Attributes = map[string]string{common.AssertionPolicyAttribute: "drop"}
+	drop, err := CheckAssertion(cv, dr, &device)
+	assert.Error(t, err)
+	assert.True(t, drop)
+
+	dr.Attributes = map[string]string{common.AssertionPolicyAttribute: "Mark"}
+	drop, err = CheckAssertion(cv, dr, &device)
+	assert.Error(t, err)
+	assert.False(t, drop)
+	assert.Equal(t, contract.OperatingState(contract.Enabled), device.OperatingState)
+
+	// the device is disabled after 2 consecutive failures, the counter being
+	// reset by a success
+	dr.Attributes = map[string]string{common.AssertionFailuresAttribute: "2"}
+	dr.Name = "Other-Level"
+	_, err = CheckAssertion(cv, dr, &device)
+	assert.Error(t, err)
+	ok, _ := dsModels.NewInt32Value("Level", 0, 10)
+	_, err = CheckAssertion(ok, dr, &device)
+	assert.NoError(t, err)
+	_, _ = CheckAssertion(cv, dr, &device)
+	assert.Equal(t, contract.OperatingState(contract.Enabled), device.OperatingState)
+	_, _ = CheckAssertion(cv, dr, &device)
+	assert.Equal(t, contract.OperatingState(contract.Disabled), device.OperatingState)
+	cached, _ := cache.Devices().ForName("Assertion-Device")
+	assert.Equal(t, contract.OperatingState(contract.Disabled), cached.OperatingState)
+}
+
+func TestAssertionReenable(t *testing.T) {
+	device := initAssertionCache(t)
+	SetAssertionConfig(AssertionConfig{ReenableAfter: 10 * time.Millisecond})
+	defer SetAssertionConfig(AssertionConfig{})
+
+	cv, _ := dsModels.NewBoolValue("Ready", 0, false)
+	dr := contract.DeviceResource{Name: "Ready", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Assertion: "true"}}}
+	_, err := CheckAssertion(cv, dr, &device)
+	assert.Error(t, err)
+	assert.Equal(t, contract.OperatingState(contract.Disabled), device.OperatingState)
+
+	assert.Eventually(t, func() bool {
+		d, _ := cache.Devices().ForName("Assertion-Device")
+		return d.OperatingState == contract.Enabled
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestAssertionReenableSkipsDevicesDisabledByOthers(t *testing.T) {
+	device := initAssertionCache(t)
+	SetAssertionConfig(AssertionConfig{ReenableAfter: 10 * time.Millisecond})
+	defer SetAssertionConfig(AssertionConfig{})
+
+	cv, _ := dsModels.NewBoolValue("Ready", 0, false)
+	dr := contract.DeviceResource{Name: "Ready", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Assertion: "true"}}}
+	_, _ = CheckAssertion(cv, dr, &device)
+	assert.Equal(t, common.DisabledByAssertion, common.DisabledBy("Assertion-Device"))
+	// the liveness monitor takes over the device before it is enabled again
+	common.SetDisabledBy("Assertion-Device", common.DisabledByLiveness)
+	defer common.ClearDisabledBy("Assertion-Device")
+
+	time.Sleep(50 * time.Millisecond)
+	d, _ := cache.Devices().ForName("Assertion-Device")
+	assert.Equal(t, contract.OperatingState(contract.Disabled), d.OperatingState)
+}
+
+func TestAssertionDisableRollback(t *testing.T) {
+	device := initAssertionCache(t)
+	common.DeviceClient = &failingDeviceClient{}
+
+	cv, _ := dsModels.NewBoolValue("Ready", 0, false)
+	dr := contract.DeviceResource{Name: "Ready", Properties: contract.ProfileProperty{Value: contract.PropertyValue{Assertion: "true"}}}
+	_, _ = CheckAssertion(cv, dr, &device)
+
+	// the cache is enabled again once Core Metadata failed to disable the device
+	assert.Eventually(t, func() bool {
+		d, _ := cache.Devices().ForName("Assertion-Device")
+		return d.OperatingState == contract.Enabled
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "", common.DisabledBy("Assertion-Device"))
+}
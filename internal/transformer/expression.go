@@ -0,0 +1,571 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/pkg/errors"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// expression is a compiled expression, evaluated for the value of a reading or
+// a parameter.
+type expression func(value float64) (float64, error)
+
+var (
+	// expressions caches the compiled expressions by source
+	expressions     = make(map[string]expression)
+	expressionMutex sync.Mutex
+)
+
+// TransformReadExpression applies the expression of the device resource to a
+// numeric reading, it is applied after TransformReadResult.
+func TransformReadExpression(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	return transformExpression(cv, dr.Attributes[common.ExpressionAttribute])
+}
+
+// TransformWriteExpression applies the inverse expression of the device resource
+// to a numeric parameter, it is applied before TransformWriteParameter. The
+// parameters of a device resource having an expression but no inverse one
+// can't be written.
+func TransformWriteExpression(cv *dsModels.CommandValue, dr contract.DeviceResource) error {
+	inverse := dr.Attributes[common.InverseExpressionAttribute]
+	if inverse == "" && dr.Attributes[common.ExpressionAttribute] != "" && isNumericCommandValue(cv) {
+		return fmt.Errorf("device resource %s has an expression but no inverse expression", dr.Name)
+	}
+	return transformExpression(cv, inverse)
+}
+
+// transformExpression evaluates the expression with the value of the
+// CommandValue, the result being rounded for the integer types.
+func transformExpression(cv *dsModels.CommandValue, source string) error {
+	if strings.TrimSpace(source) == "" || !isNumericCommandValue(cv) {
+		return nil
+	}
+
+	e, err := compileExpression(source)
+	if err != nil {
+		return fmt.Errorf("invalid expression (%s) of device resource %s: %v", source, cv.DeviceResourceName, err)
+	}
+	value, err := commandValueForTransform(cv)
+	if err != nil {
+		return err
+	}
+	result, err := e(toFloat64(value))
+	if err != nil {
+		return fmt.Errorf("expression (%s) failed for device resource %s: %v", source, cv.DeviceResourceName, err)
+	}
+	if !checkTransformedValueInRange(value, result) {
+		return errors.Wrap(NewOverflowError(value, result), fmt.Sprintf("Overflow failed for device resource '%v' ", cv.DeviceResourceName))
+	}
+	return replaceNewCommandValue(cv, fromFloat64(value, result))
+}
+
+func isNumericCommandValue(cv *dsModels.CommandValue) bool {
+	switch cv.Type {
+	case dsModels.Uint8, dsModels.Uint16, dsModels.Uint32, dsModels.Uint64,
+		dsModels.Int8, dsModels.Int16, dsModels.Int32, dsModels.Int64,
+		dsModels.Float32, dsModels.Float64:
+		return true
+	}
+	return false
+}
+
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return math.NaN()
+}
+
+// fromFloat64 converts f to the type of origin, rounding it for the integer
+// types so that 24.999999 becomes 25 rather than 24.
+func fromFloat64(origin interface{}, f float64) interface{} {
+	switch origin.(type) {
+	case uint8:
+		return uint8(math.Round(f))
+	case uint16:
+		return uint16(math.Round(f))
+	case uint32:
+		return uint32(math.Round(f))
+	case uint64:
+		return uint64(math.Round(f))
+	case int8:
+		return int8(math.Round(f))
+	case int16:
+		return int16(math.Round(f))
+	case int32:
+		return int32(math.Round(f))
+	case int64:
+		return int64(math.Round(f))
+	case float32:
+		return float32(f)
+	}
+	return f
+}
+
+// compileExpression parses an expression of the variable value (or x), made of:
+//
+//	numbers        decimal, hexadecimal (0x1F) or scientific (1.5e3), and the constants pi and e
+//	+ - * / %      arithmetic, % being the floating point remainder
+//	^              power, which is right associative
+//	& | << >>      bitwise operators on integers
+//	abs, sqrt, exp, ln, log10, floor, ceil, round of one argument
+//	min, max, pow  of several arguments
+//	poly(x, c0, c1, ..., cn)        the polynomial c0 + c1*x + ... + cn*x^n
+//	interp(x, x0, y0, x1, y1, ...)  the linear interpolation of x in a lookup
+//	                                table, clamped to its first and last points
+//	bits(x, offset, width)          the width bits of x starting at bit offset
+func compileExpression(source string) (expression, error) {
+	expressionMutex.Lock()
+	defer expressionMutex.Unlock()
+
+	if e, ok := expressions[source]; ok {
+		return e, nil
+	}
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	expressions[source] = e
+	return e, nil
+}
+
+func tokenize(source string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case strings.HasPrefix(source[i:], "0x") || strings.HasPrefix(source[i:], "0X"):
+			i += 2
+			for i < len(source) && strings.ContainsRune("0123456789abcdefABCDEF", rune(source[i])) {
+				i++
+			}
+		case unicode.IsDigit(c) || c == '.':
+			for i < len(source) && (unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			if i < len(source) && (source[i] == 'e' || source[i] == 'E') {
+				j := i + 1
+				if j < len(source) && (source[j] == '+' || source[j] == '-') {
+					j++
+				}
+				if j < len(source) && unicode.IsDigit(rune(source[j])) {
+					for i = j; i < len(source) && unicode.IsDigit(rune(source[i])); i++ {
+					}
+				}
+			}
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "<<") || strings.HasPrefix(source[i:], ">>"):
+			i += 2
+		case strings.ContainsRune("+-*/%^&|(),", c):
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+		tokens = append(tokens, source[start:i])
+	}
+	return tokens, nil
+}
+
+// parser is a recursive descent parser of the expressions, whose operators
+// are, from the lowest to the highest precedence: |, &, << >>, + -, * / %,
+// unary - and ^.
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) expect(token string) error {
+	if p.peek() != token {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("missing %q", token)
+		}
+		return fmt.Errorf("expected %q instead of %q", token, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// parseBinary parses the left associative operators of a precedence level.
+func (p *parser) parseBinary(operand func() (expression, error), operators ...string) (expression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		found := false
+		for _, o := range operators {
+			found = found || op == o
+		}
+		if !found {
+			return left, nil
+		}
+		p.pos++
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpression(op, left, right)
+	}
+}
+
+func (p *parser) parseOr() (expression, error) {
+	return p.parseBinary(p.parseAnd, "|")
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	return p.parseBinary(p.parseShift, "&")
+}
+
+func (p *parser) parseShift() (expression, error) {
+	return p.parseBinary(p.parseSum, "<<", ">>")
+}
+
+func (p *parser) parseSum() (expression, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *parser) parseProduct() (expression, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	switch p.peek() {
+	case "-":
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(value float64) (float64, error) {
+			v, err := operand(value)
+			return -v, err
+		}, nil
+	case "+":
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (expression, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "^" {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return binaryExpression("^", base, exponent), nil
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	token := p.peek()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch c := rune(token[0]); {
+	case token == "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case unicode.IsDigit(c) || c == '.':
+		n, err := parseNumber(token)
+		if err != nil {
+			return nil, err
+		}
+		return constant(n), nil
+	case unicode.IsLetter(c) || c == '_':
+		if p.peek() == "(" {
+			return p.parseCall(token)
+		}
+		switch token {
+		case "value", "x":
+			return func(value float64) (float64, error) { return value, nil }, nil
+		case "pi":
+			return constant(math.Pi), nil
+		case "e":
+			return constant(math.E), nil
+		}
+		return nil, fmt.Errorf("unknown variable %q", token)
+	}
+	return nil, fmt.Errorf("unexpected %q", token)
+}
+
+func (p *parser) parseCall(name string) (expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []expression
+	for p.peek() != ")" {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.pos++
+	return function(name, args)
+}
+
+func parseNumber(token string) (float64, error) {
+	if strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X") {
+		n, err := strconv.ParseUint(token[2:], 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", token)
+		}
+		return float64(n), nil
+	}
+	n, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", token)
+	}
+	return n, nil
+}
+
+func constant(n float64) expression {
+	return func(float64) (float64, error) { return n, nil }
+}
+
+func binaryExpression(op string, left expression, right expression) expression {
+	return func(value float64) (float64, error) {
+		l, err := left(value)
+		if err != nil {
+			return 0, err
+		}
+		r, err := right(value)
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return l / r, nil
+		case "%":
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return math.Mod(l, r), nil
+		case "^":
+			return math.Pow(l, r), nil
+		}
+		return integerOperation(op, l, r)
+	}
+}
+
+func integerOperation(op string, l float64, r float64) (float64, error) {
+	li, err := toInteger(op, l)
+	if err != nil {
+		return 0, err
+	}
+	ri, err := toInteger(op, r)
+	if err != nil {
+		return 0, err
+	}
+	switch op {
+	case "&":
+		return float64(li & ri), nil
+	case "|":
+		return float64(li | ri), nil
+	}
+	if ri < 0 || ri > 63 {
+		return 0, fmt.Errorf("shift count %d out of range", ri)
+	}
+	if op == "<<" {
+		return float64(li << uint(ri)), nil
+	}
+	return float64(li >> uint(ri)), nil
+}
+
+func toInteger(op string, f float64) (int64, error) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("operand %v of %s isn't an integer", f, op)
+	}
+	return int64(f), nil
+}
+
+func function(name string, args []expression) (expression, error) {
+	var arity func(n int) bool
+	var f func(a []float64) (float64, error)
+
+	if math1, ok := map[string]func(float64) float64{
+		"abs": math.Abs, "sqrt": math.Sqrt, "exp": math.Exp, "ln": math.Log, "log10": math.Log10,
+		"floor": math.Floor, "ceil": math.Ceil, "round": math.Round,
+	}[name]; ok {
+		arity = func(n int) bool { return n == 1 }
+		f = func(a []float64) (float64, error) { return math1(a[0]), nil }
+	} else {
+		switch name {
+		case "min", "max":
+			arity = func(n int) bool { return n >= 1 }
+			f = func(a []float64) (float64, error) {
+				result := a[0]
+				for _, v := range a[1:] {
+					if name == "min" {
+						result = math.Min(result, v)
+					} else {
+						result = math.Max(result, v)
+					}
+				}
+				return result, nil
+			}
+		case "pow":
+			arity = func(n int) bool { return n == 2 }
+			f = func(a []float64) (float64, error) { return math.Pow(a[0], a[1]), nil }
+		case "poly":
+			arity = func(n int) bool { return n >= 2 }
+			f = poly
+		case "interp":
+			arity = func(n int) bool { return n >= 5 && n%2 == 1 }
+			f = interp
+		case "bits":
+			arity = func(n int) bool { return n == 3 }
+			f = bits
+		default:
+			return nil, fmt.Errorf("unknown function %q", name)
+		}
+	}
+	if !arity(len(args)) {
+		return nil, fmt.Errorf("wrong number of arguments (%d) for %s", len(args), name)
+	}
+
+	return func(value float64) (float64, error) {
+		a := make([]float64, len(args))
+		for i, arg := range args {
+			v, err := arg(value)
+			if err != nil {
+				return 0, err
+			}
+			a[i] = v
+		}
+		return f(a)
+	}, nil
+}
+
+// poly evaluates the polynomial of coefficients a[1:] for a[0].
+func poly(a []float64) (float64, error) {
+	result := 0.0
+	for i := len(a) - 1; i >= 1; i-- {
+		result = result*a[0] + a[i]
+	}
+	return result, nil
+}
+
+// interp interpolates a[0] in the table of points a[1:], whose x must be
+// increasing.
+func interp(a []float64) (float64, error) {
+	x, points := a[0], a[1:]
+	for i := 2; i < len(points); i += 2 {
+		if points[i] <= points[i-2] {
+			return 0, fmt.Errorf("the x of the interp points must be increasing")
+		}
+	}
+	if x <= points[0] {
+		return points[1], nil
+	}
+	for i := 2; i < len(points); i += 2 {
+		if x <= points[i] {
+			x0, y0, x1, y1 := points[i-2], points[i-1], points[i], points[i+1]
+			return y0 + (x-x0)*(y1-y0)/(x1-x0), nil
+		}
+	}
+	return points[len(points)-1], nil
+}
+
+// bits extracts a[2] bits of a[0] from bit a[1].
+func bits(a []float64) (float64, error) {
+	v, err := toInteger("bits", a[0])
+	if err != nil {
+		return 0, err
+	}
+	offset, err := toInteger("bits", a[1])
+	if err != nil {
+		return 0, err
+	}
+	width, err := toInteger("bits", a[2])
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 || width < 1 || offset+width > 64 {
+		return 0, fmt.Errorf("bits offset %d and width %d out of range", offset, width)
+	}
+	return float64(uint64(v) >> uint(offset) & (1<<uint(width) - 1)), nil
+}
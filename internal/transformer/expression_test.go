@@ -0,0 +1,104 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestCompileExpression(t *testing.T) {
+	tests := []struct {
+		source   string
+		value    float64
+		expected float64
+	}{
+		{"value", 3, 3},
+		{"(x - 32) * 5 / 9", 212, 100},
+		{"1 + 2 * 3 - 4 / 2", 0, 5},
+		{"-2 ^ 2", 0, -4},
+		{"2 ^ 3 ^ 2", 0, 512},
+		{"2 ^ -1", 0, 0.5},
+		{"7 % 4", 0, 3},
+		{"1.5e3 + 0x10", 0, 1516},
+		{"value >> 4 & 0xF", 0xAB, 0xA},
+		{"1 << 3 | 1", 0, 9},
+		{"bits(value, 4, 4)", 0xAB, 0xA},
+		{"poly(value, 1, 2, 3)", 2, 17},
+		{"interp(value, 0, 0, 10, 100, 20, 400)", 15, 250},
+		{"interp(value, 0, 0, 10, 100)", -5, 0},
+		{"interp(value, 0, 0, 10, 100)", 50, 100},
+		{"max(min(value, 10), 0)", 42, 10},
+		{"round(abs(value))", -2.5, 3},
+		{"sqrt(pow(value, 2))", -4, 4},
+	}
+	for _, tt := range tests {
+		e, err := compileExpression(tt.source)
+		require.NoError(t, err, tt.source)
+		result, err := e(tt.value)
+		require.NoError(t, err, tt.source)
+		assert.InDelta(t, tt.expected, result, 1e-9, tt.source)
+	}
+
+	for _, source := range []string{"", "value +", "(value", "value)", "y", "foo(1)", "abs(1, 2)", "interp(x, 0, 0)", "value $ 2"} {
+		_, err := compileExpression(source)
+		assert.Error(t, err, source)
+	}
+
+	for _, source := range []string{"value / 0", "value & 1.5", "1 << 64", "interp(x, 1, 0, 0, 1)"} {
+		e, err := compileExpression(source)
+		require.NoError(t, err, source)
+		_, err = e(1)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestTransformReadExpression(t *testing.T) {
+	dr := contract.DeviceResource{Name: "Temperature", Attributes: map[string]string{common.ExpressionAttribute: "(value - 32) * 5 / 9"}}
+
+	cv, _ := dsModels.NewInt16Value(dr.Name, 0, 77)
+	require.NoError(t, TransformReadExpression(cv, dr))
+	v, _ := cv.Int16Value()
+	assert.Equal(t, int16(25), v)
+
+	cv, _ = dsModels.NewFloat64Value(dr.Name, 0, 32)
+	require.NoError(t, TransformReadExpression(cv, dr))
+	f, _ := cv.Float64Value()
+	assert.Equal(t, float64(0), f)
+
+	// the result must fit in the type of the reading
+	cv, _ = dsModels.NewUint8Value(dr.Name, 0, 0)
+	err := TransformReadExpression(cv, dr)
+	_, ok := errors.Cause(err).(OverflowError)
+	assert.True(t, ok)
+
+	cv = dsModels.NewStringValue(dr.Name, 0, "77")
+	assert.NoError(t, TransformReadExpression(cv, dr))
+	assert.Equal(t, "77", cv.ValueToString())
+}
+
+func TestTransformWriteExpression(t *testing.T) {
+	dr := contract.DeviceResource{Name: "Temperature", Attributes: map[string]string{
+		common.ExpressionAttribute:        "(value - 32) * 5 / 9",
+		common.InverseExpressionAttribute: "value * 9 / 5 + 32",
+	}}
+	cv, _ := dsModels.NewInt32Value(dr.Name, 0, 25)
+	require.NoError(t, TransformWriteExpression(cv, dr))
+	v, _ := cv.Int32Value()
+	assert.Equal(t, int32(77), v)
+
+	delete(dr.Attributes, common.InverseExpressionAttribute)
+	cv, _ = dsModels.NewInt32Value(dr.Name, 0, 25)
+	assert.Error(t, TransformWriteExpression(cv, dr))
+}
@@ -8,17 +8,14 @@ package transformer
 
 import (
 	"bytes"
-	"context"
 	"encoding/binary"
 	"fmt"
 	"math"
 	"strconv"
 
-	"github.com/edgexfoundry/device-sdk-go/internal/cache"
 	"github.com/edgexfoundry/device-sdk-go/internal/common"
 	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -596,24 +593,12 @@ func replaceNewCommandValue(cv *dsModels.CommandValue, newValue interface{}) err
 	return err
 }
 
-func CheckAssertion(cv *dsModels.CommandValue, assertion string, device *contract.Device) error {
-	if assertion != "" && cv.ValueToString() != assertion {
-		device.OperatingState = contract.Disabled
-		cache.Devices().Update(*device)
-		ctx := context.WithValue(context.Background(), common.CorrelationHeader, uuid.New().String())
-		go common.DeviceClient.UpdateOpStateByName(ctx, device.Name, contract.Disabled)
-		msg := fmt.Sprintf("assertion (%s) failed with value: %s", assertion, cv.ValueToString())
-		common.LoggingClient.Error(msg)
-		return fmt.Errorf(msg)
-	}
-	return nil
-}
-
 func MapCommandValue(value *dsModels.CommandValue, mappings map[string]string) (*dsModels.CommandValue, bool) {
 	newValue, ok := mappings[value.ValueToString()]
 	var result *dsModels.CommandValue
 	if ok {
 		result = dsModels.NewStringValue(value.DeviceResourceName, value.Origin, newValue)
+		result.SetQuality(value.Quality, value.QualityReason)
 	}
 	return result, ok
 }
@@ -0,0 +1,41 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"bytes"
+	"math"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// VerifyWrittenValue tells whether the value read back from a device resource
+// matches the parameter written to it. Numeric values match when they differ
+// by at most tolerance, which is a percentage of the parameter if percent is
+// set. Other values must be equal.
+func VerifyWrittenValue(written *dsModels.CommandValue, read *dsModels.CommandValue, tolerance float64, percent bool) (bool, error) {
+	if !isNumericCommandValue(written) || !isNumericCommandValue(read) {
+		if written.Type == dsModels.Binary || read.Type == dsModels.Binary {
+			return written.Type == read.Type && bytes.Equal(written.BinValue, read.BinValue), nil
+		}
+		return written.ValueToString() == read.ValueToString(), nil
+	}
+
+	w, err := commandValueForTransform(written)
+	if err != nil {
+		return false, err
+	}
+	r, err := commandValueForTransform(read)
+	if err != nil {
+		return false, err
+	}
+	limit := tolerance
+	if percent {
+		limit = math.Abs(toFloat64(w)) * tolerance / 100
+	}
+	return math.Abs(toFloat64(r)-toFloat64(w)) <= limit, nil
+}
@@ -0,0 +1,46 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+func TestVerifyWrittenValue(t *testing.T) {
+	written, _ := dsModels.NewFloat32Value("Setpoint", 0, 20)
+	read, _ := dsModels.NewFloat64Value("Setpoint", 0, 20.4)
+
+	ok, err := VerifyWrittenValue(written, read, 0, false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = VerifyWrittenValue(written, read, 0.5, false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = VerifyWrittenValue(written, read, 1, true)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = VerifyWrittenValue(written, read, 2, true)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _ = VerifyWrittenValue(dsModels.NewStringValue("Mode", 0, "auto"), dsModels.NewStringValue("Mode", 0, "auto"), 0, false)
+	assert.True(t, ok)
+	ok, _ = VerifyWrittenValue(dsModels.NewStringValue("Mode", 0, "auto"), dsModels.NewStringValue("Mode", 0, "manual"), 0, false)
+	assert.False(t, ok)
+
+	b1, _ := dsModels.NewBinaryValue("Blob", 0, []byte{1, 2})
+	b2, _ := dsModels.NewBinaryValue("Blob", 0, []byte{1, 3})
+	ok, _ = VerifyWrittenValue(b1, b1, 0, false)
+	assert.True(t, ok)
+	ok, _ = VerifyWrittenValue(b1, b2, 0, false)
+	assert.False(t, ok)
+}
@@ -0,0 +1,326 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package validator checks device profiles for semantic errors which would
+// otherwise only surface when commands are executed, e.g. deviceCommands
+// referencing unknown deviceResources or transforms which can't be applied.
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	yamlExt = ".yaml"
+	ymlExt  = ".yml"
+	jsonExt = ".json"
+)
+
+// valueTypes lists the value types supported by the SDK, in upper case.
+var valueTypes = map[string]bool{
+	"BOOL": true, "BOOLARRAY": true, "STRING": true, "BINARY": true,
+	"UINT8": true, "UINT8ARRAY": true, "UINT16": true, "UINT16ARRAY": true,
+	"UINT32": true, "UINT32ARRAY": true, "UINT64": true, "UINT64ARRAY": true,
+	"INT8": true, "INT8ARRAY": true, "INT16": true, "INT16ARRAY": true,
+	"INT32": true, "INT32ARRAY": true, "INT64": true, "INT64ARRAY": true,
+	"FLOAT32": true, "FLOAT32ARRAY": true, "FLOAT64": true, "FLOAT64ARRAY": true,
+}
+
+// bitwiseTypes lists the value types mask and shift are applied to.
+var bitwiseTypes = map[string]bool{"UINT8": true, "UINT16": true, "UINT32": true, "UINT64": true}
+
+// Issue is a single problem found in a device profile.
+type Issue struct {
+	// Location identifies the offending element, e.g. "deviceResources[Temperature].properties.value.scale".
+	Location string `json:"location"`
+	// Message describes the problem.
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Location, i.Message)
+}
+
+// Report is the outcome of the validation of one device profile.
+type Report struct {
+	// Source is the file the profile was read from, if any.
+	Source string `json:"source,omitempty"`
+	// Profile is the name of the profile.
+	Profile string  `json:"profile"`
+	Valid   bool    `json:"valid"`
+	Issues  []Issue `json:"issues"`
+}
+
+func (r Report) String() string {
+	name := r.Profile
+	if r.Source != "" {
+		name = fmt.Sprintf("%s (%s)", r.Profile, r.Source)
+	}
+	if r.Valid {
+		return fmt.Sprintf("%s: valid", name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d issue(s)", name, len(r.Issues))
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&b, "\n  - %s", issue)
+	}
+	return b.String()
+}
+
+// Parse decodes a device profile from YAML or JSON, JSON being a subset of YAML.
+func Parse(data []byte) (contract.DeviceProfile, error) {
+	var profile contract.DeviceProfile
+	err := yaml.Unmarshal(data, &profile)
+	return profile, err
+}
+
+// IsProfileFile tells whether name has the extension of a device profile
+// file, i.e. YAML or JSON.
+func IsProfileFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case yamlExt, ymlExt, jsonExt:
+		return true
+	}
+	return false
+}
+
+// ParseFile decodes a device profile read from the file name, according to
+// the extension of the file.
+func ParseFile(name string, data []byte) (contract.DeviceProfile, error) {
+	var profile contract.DeviceProfile
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case jsonExt:
+		err = json.Unmarshal(data, &profile)
+	default:
+		return Parse(data)
+	}
+	return profile, err
+}
+
+// IsProfile tells whether a decoded file defines a device profile rather than
+// another kind of document sharing the directory, such as a provision watcher,
+// i.e. whether it has deviceResources, deviceCommands or coreCommands.
+func IsProfile(profile contract.DeviceProfile) bool {
+	return len(profile.DeviceResources) > 0 || len(profile.DeviceCommands) > 0 || len(profile.CoreCommands) > 0
+}
+
+// ValidateBytes parses and validates a device profile.
+func ValidateBytes(data []byte) Report {
+	return validateFile("", data)
+}
+
+func validateFile(name string, data []byte) Report {
+	profile, err := ParseFile(name, data)
+	if err != nil {
+		return Report{Issues: []Issue{{Location: "profile", Message: fmt.Sprintf("cannot be parsed: %v", err)}}}
+	}
+	return Validate(profile)
+}
+
+// ValidateDir validates every profile file in dir, skipping the files which
+// don't define a device profile.
+func ValidateDir(dir string) ([]Report, error) {
+	fileInfo, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []Report
+	for _, file := range fileInfo {
+		if file.IsDir() || !IsProfileFile(file.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		data, err := ioutil.ReadFile(path)
+		var r Report
+		if err != nil {
+			r = Report{Issues: []Issue{{Location: "profile", Message: fmt.Sprintf("cannot be read: %v", err)}}}
+		} else if profile, err := ParseFile(file.Name(), data); err == nil && !IsProfile(profile) {
+			continue
+		} else {
+			r = validateFile(file.Name(), data)
+		}
+		r.Source = path
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// Validate checks a device profile and reports every problem found.
+func Validate(profile contract.DeviceProfile) Report {
+	v := &validation{}
+
+	if strings.TrimSpace(profile.Name) == "" {
+		v.add("name", "profile name must not be empty")
+	}
+
+	resources := make(map[string]contract.DeviceResource, len(profile.DeviceResources))
+	for i, dr := range profile.DeviceResources {
+		location := fmt.Sprintf("deviceResources[%s]", dr.Name)
+		if dr.Name == "" {
+			location = fmt.Sprintf("deviceResources[%d]", i)
+			v.add(location, "name must not be empty")
+		} else if _, ok := resources[dr.Name]; ok {
+			v.add(location, "duplicate deviceResource name")
+		}
+		resources[dr.Name] = dr
+		v.checkPropertyValue(location+".properties.value", dr.Properties.Value)
+	}
+
+	commands := make(map[string]contract.ProfileResource, len(profile.DeviceCommands))
+	for i, pr := range profile.DeviceCommands {
+		location := fmt.Sprintf("deviceCommands[%s]", pr.Name)
+		if pr.Name == "" {
+			v.add(fmt.Sprintf("deviceCommands[%d]", i), "name must not be empty")
+		} else if _, ok := commands[pr.Name]; ok {
+			v.add(location, "duplicate deviceCommand name")
+		}
+		commands[pr.Name] = pr
+	}
+	for _, pr := range profile.DeviceCommands {
+		v.checkResourceOperations(fmt.Sprintf("deviceCommands[%s].get", pr.Name), pr.Get, resources, commands)
+		v.checkResourceOperations(fmt.Sprintf("deviceCommands[%s].set", pr.Name), pr.Set, resources, commands)
+	}
+	v.checkChaining("get", resources, commands, func(pr contract.ProfileResource) []contract.ResourceOperation { return pr.Get })
+	v.checkChaining("set", resources, commands, func(pr contract.ProfileResource) []contract.ResourceOperation { return pr.Set })
+
+	coreCommands := make(map[string]bool, len(profile.CoreCommands))
+	for _, cmd := range profile.CoreCommands {
+		location := fmt.Sprintf("coreCommands[%s]", cmd.Name)
+		if coreCommands[cmd.Name] {
+			v.add(location, "duplicate coreCommand name")
+		}
+		coreCommands[cmd.Name] = true
+		_, isCommand := commands[cmd.Name]
+		_, isResource := resources[cmd.Name]
+		if !isCommand && !isResource {
+			v.add(location, "does not match any deviceCommand or deviceResource")
+		}
+	}
+
+	return Report{Profile: profile.Name, Valid: len(v.issues) == 0, Issues: v.issues}
+}
+
+type validation struct {
+	issues []Issue
+}
+
+func (v *validation) add(location string, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validation) checkPropertyValue(location string, pv contract.PropertyValue) {
+	valueType := strings.ToUpper(pv.Type)
+	if pv.Type == "" {
+		v.add(location+".type", "type must not be empty")
+	} else if !valueTypes[valueType] {
+		v.add(location+".type", "unknown type %s", pv.Type)
+	}
+
+	numeric := map[string]string{"scale": pv.Scale, "offset": pv.Offset, "base": pv.Base, "minimum": pv.Minimum, "maximum": pv.Maximum}
+	for _, field := range []string{"scale", "offset", "base", "minimum", "maximum"} {
+		value := numeric[field]
+		if value == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			v.add(location+"."+field, "%s is not a number", value)
+		}
+	}
+
+	if pv.Mask != "" && pv.Mask != "0" {
+		if _, err := strconv.ParseUint(pv.Mask, 10, 64); err != nil {
+			v.add(location+".mask", "%s is not an unsigned integer", pv.Mask)
+		}
+		if valueType != "" && !bitwiseTypes[valueType] {
+			v.add(location+".mask", "mask is only applied to unsigned integer types, not %s", pv.Type)
+		}
+	}
+	if pv.Shift != "" && pv.Shift != "0" {
+		if _, err := strconv.ParseInt(pv.Shift, 10, 64); err != nil {
+			v.add(location+".shift", "%s is not an integer", pv.Shift)
+		}
+		if valueType != "" && !bitwiseTypes[valueType] {
+			v.add(location+".shift", "shift is only applied to unsigned integer types, not %s", pv.Type)
+		}
+	}
+}
+
+func (v *validation) checkResourceOperations(location string, ros []contract.ResourceOperation, resources map[string]contract.DeviceResource, commands map[string]contract.ProfileResource) {
+	for i, ro := range ros {
+		name := ro.DeviceResource
+		if name == "" {
+			name = ro.Object
+		}
+		roLocation := fmt.Sprintf("%s[%d]", location, i)
+		if name == "" {
+			v.add(roLocation, "deviceResource must not be empty")
+			continue
+		}
+		if _, ok := resources[name]; ok {
+			continue
+		}
+		if _, ok := commands[name]; !ok {
+			v.add(roLocation, "references %s which is neither a deviceResource nor a deviceCommand", name)
+		}
+	}
+}
+
+// checkChaining reports deviceCommands which reference each other in a cycle
+// through the resource operations returned by ops.
+func (v *validation) checkChaining(method string, resources map[string]contract.DeviceResource, commands map[string]contract.ProfileResource, ops func(contract.ProfileResource) []contract.ResourceOperation) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(commands))
+	var visit func(name string, path []string) bool
+	visit = func(name string, path []string) bool {
+		switch state[name] {
+		case visiting:
+			v.add(fmt.Sprintf("deviceCommands[%s].%s", name, method), "command chain cycle %s -> %s", strings.Join(path, " -> "), name)
+			return false
+		case done:
+			return true
+		}
+		state[name] = visiting
+		for _, ro := range ops(commands[name]) {
+			if _, ok := resources[ro.DeviceResource]; ok {
+				continue
+			}
+			if _, ok := commands[ro.DeviceResource]; ok {
+				if !visit(ro.DeviceResource, append(path, name)) {
+					// the cycle has been reported once, don't report it again
+					state[name] = done
+					return false
+				}
+			}
+		}
+		state[name] = done
+		return true
+	}
+	for _, name := range names {
+		if state[name] == 0 {
+			visit(name, nil)
+		}
+	}
+}
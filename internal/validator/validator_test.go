@@ -0,0 +1,120 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validProfile = `
+name: "Sensor"
+deviceResources:
+  - name: "Temperature"
+    properties:
+      value: { type: "Float32", scale: "0.1", offset: "-40" }
+  - name: "Status"
+    properties:
+      value: { type: "Uint16", mask: "255", shift: "-8" }
+deviceCommands:
+  - name: "Values"
+    get:
+      - { deviceResource: "Temperature" }
+      - { deviceResource: "Status" }
+  - name: "All"
+    get:
+      - { deviceResource: "Values" }
+coreCommands:
+  - name: "Values"
+`
+
+func TestValidate_ValidProfile(t *testing.T) {
+	r := ValidateBytes([]byte(validProfile))
+	assert.True(t, r.Valid, r.String())
+	assert.Equal(t, "Sensor", r.Profile)
+}
+
+func TestValidate_Issues(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  string
+		location string
+	}{
+		{"UnknownType", `{name: P, deviceResources: [{name: R, properties: {value: {type: Float}}}]}`, "deviceResources[R].properties.value.type"},
+		{"EmptyType", `{name: P, deviceResources: [{name: R, properties: {value: {}}}]}`, "deviceResources[R].properties.value.type"},
+		{"NonNumericScale", `{name: P, deviceResources: [{name: R, properties: {value: {type: Int32, scale: ten}}}]}`, "deviceResources[R].properties.value.scale"},
+		{"NonNumericBase", `{name: P, deviceResources: [{name: R, properties: {value: {type: Int32, base: e}}}]}`, "deviceResources[R].properties.value.base"},
+		{"MaskOnFloat", `{name: P, deviceResources: [{name: R, properties: {value: {type: Float64, mask: "255"}}}]}`, "deviceResources[R].properties.value.mask"},
+		{"ShiftOnFloat", `{name: P, deviceResources: [{name: R, properties: {value: {type: Float32, shift: "2"}}}]}`, "deviceResources[R].properties.value.shift"},
+		{"DuplicateResource", `{name: P, deviceResources: [{name: R, properties: {value: {type: Int32}}}, {name: R, properties: {value: {type: Int32}}}]}`, "deviceResources[R]"},
+		{"DuplicateCommand", `{name: P, deviceCommands: [{name: C}, {name: C}]}`, "deviceCommands[C]"},
+		{"UnknownResource", `{name: P, deviceCommands: [{name: C, get: [{deviceResource: Missing}]}]}`, "deviceCommands[C].get[0]"},
+		{"ChainCycle", `{name: P, deviceCommands: [{name: A, set: [{deviceResource: B}]}, {name: B, set: [{deviceResource: A}]}]}`, "deviceCommands[A].set"},
+		{"UnknownCoreCommand", `{name: P, coreCommands: [{name: C}]}`, "coreCommands[C]"},
+		{"EmptyName", `{deviceResources: []}`, "name"},
+		{"Malformed", `name: [`, "profile"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ValidateBytes([]byte(tt.profile))
+			require.False(t, r.Valid)
+			var locations []string
+			for _, issue := range r.Issues {
+				locations = append(locations, issue.Location)
+			}
+			assert.Contains(t, locations, tt.location)
+		})
+	}
+}
+
+func TestValidateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "valid.yaml"), []byte(validProfile), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.yml"), []byte(`{name: P, deviceCommands: [{name: C, get: [{deviceResource: Missing}]}]}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a profile"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "provisionwatcher.json"), []byte(`{"name": "watcher", "identifiers": {"Address": "simple[0-9]+"}}`), 0644))
+
+	reports, err := ValidateDir(dir)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	valid := map[string]bool{}
+	for _, r := range reports {
+		valid[filepath.Base(r.Source)] = r.Valid
+	}
+	assert.Equal(t, map[string]bool{"valid.yaml": true, "invalid.yml": false}, valid)
+}
+
+func TestParseFile(t *testing.T) {
+	jsonProfile := `{"name": "Sensor", "deviceResources": [{"name": "Temperature", "properties": {"value": {"type": "Float32", "scale": "0.1"}}}],
+		"deviceCommands": [{"name": "Values", "get": [{"deviceResource": "Temperature"}]}]}`
+	for _, file := range []struct{ name, data string }{{"sensor.json", jsonProfile}, {"sensor.yaml", validProfile}} {
+		t.Run(file.name, func(t *testing.T) {
+			profile, err := ParseFile(file.name, []byte(file.data))
+			require.NoError(t, err)
+			assert.Equal(t, "Sensor", profile.Name)
+			require.NotEmpty(t, profile.DeviceResources)
+			assert.Equal(t, "0.1", profile.DeviceResources[0].Properties.Value.Scale)
+			require.NotEmpty(t, profile.DeviceCommands)
+			assert.Equal(t, "Temperature", profile.DeviceCommands[0].Get[0].DeviceResource)
+			assert.True(t, Validate(profile).Valid)
+		})
+	}
+
+	_, err := ParseFile("sensor.json", []byte(`name: Sensor`))
+	assert.Error(t, err)
+	assert.True(t, IsProfileFile("Sensor.JSON"))
+	assert.False(t, IsProfileFile("notes.txt"))
+	assert.False(t, IsProfileFile("configuration.toml"))
+}
@@ -0,0 +1,42 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+// Results of the execution of an AutoEvent reported by AutoEventStatus.
+const (
+	// AutoEventPublished means an event has been published.
+	AutoEventPublished = "Published"
+	// AutoEventUnchanged means the readings of an OnChange AutoEvent haven't
+	// changed, so no event has been published.
+	AutoEventUnchanged = "Unchanged"
+	// AutoEventNoEvent means the command returned no readings.
+	AutoEventNoEvent = "NoEvent"
+	// AutoEventFailed means the command failed, see LastError.
+	AutoEventFailed = "Failed"
+)
+
+// AutoEventStatus reports the state of the executor of an AutoEvent.
+type AutoEventStatus struct {
+	Device    string `json:"device"`
+	Resource  string `json:"resource"`
+	Frequency string `json:"frequency"`
+	OnChange  bool   `json:"onChange"`
+	// Paused indicates whether the scheduled executions are skipped.
+	Paused bool `json:"paused"`
+	// NextRun is the time of the next scheduled execution, in milliseconds
+	// since the epoch.
+	NextRun int64 `json:"nextRun,omitempty"`
+	// LastRun is the time of the last execution, in milliseconds since the epoch.
+	LastRun int64 `json:"lastRun,omitempty"`
+	// LastResult is the result of the last execution, one of the AutoEvent
+	// result constants.
+	LastResult string `json:"lastResult,omitempty"`
+	// LastError is the error of the last execution if it failed.
+	LastError string `json:"lastError,omitempty"`
+	// Failures is the number of consecutive failed executions.
+	Failures int `json:"failures,omitempty"`
+}
@@ -0,0 +1,55 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+// DeviceCommandRequests holds the requests of a command for one of the
+// devices of a batch passed to a BatchProtocolDriver.
+type DeviceCommandRequests struct {
+	// DeviceName is the name of the Device
+	DeviceName string
+	// Protocols are the protocol properties of the Device
+	Protocols map[string]contract.ProtocolProperties
+	// Requests are the requests of the command, one per Device Resource
+	Requests []CommandRequest
+	// Params are the values to write, matching Requests, for write commands only
+	Params []*CommandValue
+}
+
+// DeviceCommandResult is the result of a batched command for one device.
+type DeviceCommandResult struct {
+	// DeviceName is the name of the Device
+	DeviceName string
+	// Values are the values read, for read commands only
+	Values []*CommandValue
+	// Err is the error of the command for this Device, nil on success
+	Err error
+}
+
+// DeviceCommandResponse is the result of a command for one of the devices
+// targeted by a command issued to several devices.
+type DeviceCommandResponse struct {
+	DeviceName string `json:"deviceName"`
+	// StatusCode is the HTTP status code the command would have returned if
+	// it had been issued to this device only.
+	StatusCode int    `json:"statusCode"`
+	Message    string `json:"message,omitempty"`
+	// Event holds the readings of a successful read command.
+	Event *Event `json:"event,omitempty"`
+}
+
+// MultiStatusResponse is the response of a command issued to several devices,
+// whose StatusCode is 200 when the command succeeded for every device, 207
+// when it only succeeded for some of them, otherwise the status code shared
+// by the failures or 500.
+type MultiStatusResponse struct {
+	StatusCode int                     `json:"statusCode"`
+	Succeeded  int                     `json:"succeeded"`
+	Failed     int                     `json:"failed"`
+	Devices    []DeviceCommandResponse `json:"devices"`
+}
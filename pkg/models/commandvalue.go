@@ -189,6 +189,23 @@ type CommandValue struct {
 	// BinValue is a binary value with a maximum capacity of 16 MB,
 	// used to hold binary values returned by a ProtocolDriver instance.
 	BinValue []byte
+	// Quality tells how reliable the value is, empty means QualityGood.
+	// It can be set by the ProtocolDriver, and is set by the SDK when a
+	// transform, an assertion or a mapping fails.
+	Quality Quality
+	// QualityReason is a code explaining a Quality other than QualityGood.
+	QualityReason string
+}
+
+// SetQuality sets the quality of the value and its reason code.
+func (cv *CommandValue) SetQuality(quality Quality, reason string) {
+	cv.Quality = quality
+	cv.QualityReason = reason
+}
+
+// IsGood tells whether the quality of the value is QualityGood.
+func (cv *CommandValue) IsGood() bool {
+	return cv.Quality == "" || cv.Quality == QualityGood
 }
 
 // NewBoolValue creates a CommandValue of Type Bool with the given value.
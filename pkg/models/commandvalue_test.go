@@ -1061,3 +1061,18 @@ func TestNewBinaryValueExceedsConstraints(t *testing.T) {
 		// PASS
 	}
 }
+
+func TestCommandValueQuality(t *testing.T) {
+	cv := NewStringValue("resource", 0, "value")
+	if !cv.IsGood() {
+		t.Errorf("CommandValue without quality should be good")
+	}
+	cv.SetQuality(QualityBad, ReasonTransformFailed)
+	if cv.IsGood() || cv.Quality != QualityBad || cv.QualityReason != ReasonTransformFailed {
+		t.Errorf("SetQuality: invalid quality: %s, reason: %s", cv.Quality, cv.QualityReason)
+	}
+	cv.SetQuality(QualityGood, "")
+	if !cv.IsGood() {
+		t.Errorf("SetQuality: CommandValue should be good")
+	}
+}
@@ -14,6 +14,18 @@ import (
 type Event struct {
 	contract.Event
 	EncodedEvent []byte
+	// Qualities holds the quality of the readings whose quality isn't
+	// QualityGood, by reading name. As contract.Reading has no quality, it
+	// isn't part of EncodedEvent.
+	Qualities map[string]ReadingQuality `json:"qualities,omitempty"`
+}
+
+// SetQuality records the quality of the reading of the given name.
+func (e *Event) SetQuality(reading string, quality ReadingQuality) {
+	if e.Qualities == nil {
+		e.Qualities = make(map[string]ReadingQuality)
+	}
+	e.Qualities[reading] = quality
 }
 
 // HasBinaryValue confirms whether an event contains one or more
@@ -0,0 +1,30 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import "context"
+
+// EventSink is a destination for the events generated by a Device Service.
+// Every event is fanned out to all the configured sinks, so the same reading
+// can for instance be pushed to Core Data and stored in a local historian.
+type EventSink interface {
+	// Name returns a short name identifying the sink in logs.
+	Name() string
+	// Send delivers the event. The event is already encoded when Send is called,
+	// EncodedEvent holds the CBOR or JSON representation pushed to Core Data.
+	// Implementations must be safe for concurrent use.
+	Send(ctx context.Context, event *Event) error
+}
+
+// EventPublisher is a generic interface to a message bus (e.g. MQTT, ZeroMQ or
+// Redis Streams). When the value passed to service.Main or startup.Bootstrap
+// implements EventPublisher, the 'Publisher' event sink uses it to publish
+// events instead of, or in addition to, pushing them to Core Data.
+type EventPublisher interface {
+	// Publish sends the payload to the given topic.
+	Publish(ctx context.Context, topic string, contentType string, payload []byte) error
+}
@@ -0,0 +1,21 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+// DeviceLiveness reports the reachability of a device monitored by the
+// liveness monitor.
+type DeviceLiveness struct {
+	Device string `json:"device"`
+	// Reachable is false once the device failed FailureThreshold consecutive
+	// pings, until a ping succeeds.
+	Reachable bool `json:"reachable"`
+	// LastSeen is the time of the last successful ping, in milliseconds since
+	// the epoch.
+	LastSeen int64 `json:"lastSeen,omitempty"`
+	// Failures is the number of consecutive failed pings.
+	Failures int `json:"failures,omitempty"`
+}
@@ -0,0 +1,56 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// NotificationType identifies the kind of change reported by a Notification.
+type NotificationType string
+
+// Types of the notifications sent to the subscribers of Service.Subscribe.
+const (
+	DeviceAdded   NotificationType = "DeviceAdded"
+	DeviceUpdated NotificationType = "DeviceUpdated"
+	DeviceRemoved NotificationType = "DeviceRemoved"
+	// DeviceAdminStateChanged is sent, after DeviceUpdated if the Device has
+	// been updated as a whole, when the AdminState of a Device changes.
+	DeviceAdminStateChanged NotificationType = "DeviceAdminStateChanged"
+	// DeviceOperatingStateChanged is sent, after DeviceUpdated if the Device
+	// has been updated as a whole, when the OperatingState of a Device changes,
+	// including when the Device Service disables it itself.
+	DeviceOperatingStateChanged NotificationType = "DeviceOperatingStateChanged"
+	// DeviceLivenessChanged is sent by the liveness monitor when a Device
+	// becomes unreachable or reachable again.
+	DeviceLivenessChanged   NotificationType = "DeviceLivenessChanged"
+	ProfileAdded            NotificationType = "ProfileAdded"
+	ProfileUpdated          NotificationType = "ProfileUpdated"
+	ProfileRemoved          NotificationType = "ProfileRemoved"
+	ProvisionWatcherAdded   NotificationType = "ProvisionWatcherAdded"
+	ProvisionWatcherUpdated NotificationType = "ProvisionWatcherUpdated"
+	ProvisionWatcherRemoved NotificationType = "ProvisionWatcherRemoved"
+)
+
+// Notification reports a change of a Device, DeviceProfile or ProvisionWatcher
+// of the Device Service. Only the Before and After fields of the kind of
+// object changed are set: Before is nil for an addition and After is nil for a
+// removal. Liveness is only set for DeviceLivenessChanged.
+type Notification struct {
+	Type NotificationType
+	// Name is the name of the object changed.
+	Name string
+	// Timestamp is the time of the change, in milliseconds since the epoch.
+	Timestamp     int64
+	DeviceBefore  *contract.Device
+	DeviceAfter   *contract.Device
+	ProfileBefore *contract.DeviceProfile
+	ProfileAfter  *contract.DeviceProfile
+	WatcherBefore *contract.ProvisionWatcher
+	WatcherAfter  *contract.ProvisionWatcher
+	Liveness      *DeviceLiveness
+}
@@ -13,6 +13,8 @@
 package models
 
 import (
+	"context"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -55,3 +57,65 @@ type ProtocolDriver interface {
 	// when a Device associated with this Device Service is removed
 	RemoveDevice(deviceName string, protocols map[string]contract.ProtocolProperties) error
 }
+
+// ContextProtocolDriver is an optional interface which can be implemented by a
+// ProtocolDriver to receive a context with its read and write commands. The
+// context is cancelled when the REST request times out (Service.Timeout) or the
+// device service shuts down, and carries the correlation ID of the request
+// which can be retrieved with clients.FromContext(ctx, clients.CorrelationHeader).
+// When implemented, these methods are called instead of HandleReadCommands and
+// HandleWriteCommands.
+type ContextProtocolDriver interface {
+	// HandleReadCommandsWithContext is the context-aware version of HandleReadCommands.
+	HandleReadCommandsWithContext(ctx context.Context, deviceName string, protocols map[string]contract.ProtocolProperties, reqs []CommandRequest) ([]*CommandValue, error)
+
+	// HandleWriteCommandsWithContext is the context-aware version of HandleWriteCommands.
+	HandleWriteCommandsWithContext(ctx context.Context, deviceName string, protocols map[string]contract.ProtocolProperties, reqs []CommandRequest, params []*CommandValue) error
+}
+
+// DevicePinger is an optional interface which can be implemented by a
+// ProtocolDriver to let the device service monitor the reachability of its
+// devices. When the Device.Liveness monitoring is enabled, Ping is called
+// periodically for every device which isn't locked.
+type DevicePinger interface {
+	// Ping checks whether the device can be reached, without reading any of
+	// its resources, and returns an error if it can't.
+	Ping(deviceName string, protocols map[string]contract.ProtocolProperties) error
+}
+
+// BatchProtocolDriver is an optional interface which can be implemented by a
+// ProtocolDriver to execute a command on several devices in a single call,
+// e.g. through the gateway they share. When implemented, the commands issued
+// to all the operational devices are passed to these methods in batches of
+// devices sharing the same protocol address (the same protocol properties
+// for the devices having none of the Concurrency AddressProperties), instead of one
+// HandleReadCommands/HandleWriteCommands call per device. A result must be
+// returned for every device of the batch.
+type BatchProtocolDriver interface {
+	// HandleBatchReadCommands reads the resources requested for every device.
+	HandleBatchReadCommands(ctx context.Context, reqs []DeviceCommandRequests) []DeviceCommandResult
+
+	// HandleBatchWriteCommands writes the parameters requested for every device.
+	HandleBatchWriteCommands(ctx context.Context, reqs []DeviceCommandRequests) []DeviceCommandResult
+}
+
+// TransactionalProtocolDriver is an optional interface which can be
+// implemented by a ProtocolDriver to write the resources of a device all at
+// once or not at all. It is used by the write commands issued in the
+// transactional write mode, whose parameters are prepared and then committed.
+// Drivers which don't implement it get their resources read before the write,
+// and the previous values written back if the write fails.
+type TransactionalProtocolDriver interface {
+	// PrepareWriteCommands validates and stages the parameters without
+	// applying them, and returns the ID of the transaction. Nothing must be
+	// left staged when it fails.
+	PrepareWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, reqs []CommandRequest, params []*CommandValue) (string, error)
+
+	// CommitWriteCommands applies all the parameters staged by the transaction.
+	// When it fails, the transaction is aborted.
+	CommitWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, transactionID string) error
+
+	// AbortWriteCommands discards the parameters staged by the transaction,
+	// leaving the resources unchanged.
+	AbortWriteCommands(deviceName string, protocols map[string]contract.ProtocolProperties, transactionID string) error
+}
@@ -0,0 +1,35 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+// Quality tells how reliable the value of a reading is.
+type Quality string
+
+const (
+	QualityGood      Quality = "Good"
+	QualityUncertain Quality = "Uncertain"
+	QualityBad       Quality = "Bad"
+)
+
+// Reason codes of the qualities set by the SDK.
+const (
+	// ReasonTransformFailed means the transform of the device resource
+	// couldn't be applied, the value is the one read by the driver.
+	ReasonTransformFailed = "TransformFailed"
+	// ReasonAssertionFailed means the value failed the assertion of the
+	// device resource.
+	ReasonAssertionFailed = "AssertionFailed"
+	// ReasonMappingFailed means the value has no mapping in the resource
+	// operation, it is left unmapped.
+	ReasonMappingFailed = "MappingFailed"
+)
+
+// ReadingQuality is the quality of a reading, with the code of its reason.
+type ReadingQuality struct {
+	Quality Quality `json:"quality"`
+	Reason  string  `json:"reason,omitempty"`
+}
@@ -0,0 +1,68 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package models
+
+import (
+	"path"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// DeviceSelector selects the devices targeted by a command issued to several
+// devices. Every criterion which is set must be matched, so that the empty
+// selector selects all the devices.
+type DeviceSelector struct {
+	// Labels are the labels a device must all have.
+	Labels []string
+	// Profile is the name of the profile of the devices.
+	Profile string
+	// NamePattern is a pattern, in the syntax of path.Match, matched by the
+	// device names, e.g. "Sensor-*".
+	NamePattern string
+	// ProtocolProperties are the values of the protocol properties a device
+	// must have, in any of its protocols, by property name.
+	ProtocolProperties map[string]string
+}
+
+// Validate checks the syntax of the name pattern.
+func (s DeviceSelector) Validate() error {
+	_, err := path.Match(s.NamePattern, "")
+	return err
+}
+
+// Matches tells whether the selector selects the device.
+func (s DeviceSelector) Matches(device contract.Device) bool {
+	if s.Profile != "" && device.Profile.Name != s.Profile {
+		return false
+	}
+	if s.NamePattern != "" {
+		if matched, err := path.Match(s.NamePattern, device.Name); err != nil || !matched {
+			return false
+		}
+	}
+	for name, value := range s.ProtocolProperties {
+		found := false
+		for _, protocol := range device.Protocols {
+			if v, ok := protocol[name]; ok && v == value {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, label := range s.Labels {
+		found := false
+		for _, l := range device.Labels {
+			found = found || l == label
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
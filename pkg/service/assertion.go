@@ -0,0 +1,38 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/transformer"
+)
+
+// startAssertions sets the default policy applied to the readings failing the
+// assertion of their device resource.
+func startAssertions() error {
+	config := common.CurrentConfig.Device.Assertion
+	policy, err := transformer.ParseAssertionPolicy(config.Policy)
+	if err != nil {
+		return err
+	}
+	if config.FailureThreshold < 0 {
+		return fmt.Errorf("assertion FailureThreshold %d cannot be negative", config.FailureThreshold)
+	}
+	reenableAfter, err := parseOptionalDuration(config.ReenableAfter)
+	if err != nil {
+		return fmt.Errorf("assertion ReenableAfter %s cannot be parsed: %v", config.ReenableAfter, err)
+	}
+
+	transformer.SetAssertionConfig(transformer.AssertionConfig{
+		Policy:           policy,
+		FailureThreshold: config.FailureThreshold,
+		ReenableAfter:    reenableAfter,
+	})
+	return nil
+}
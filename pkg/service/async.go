@@ -38,6 +38,7 @@ func processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 			return
 		case acv := <-svc.asyncCh:
 			readings := make([]contract.Reading, 0, len(acv.CommandValues))
+			qualities := make(map[string]dsModels.ReadingQuality)
 
 			device, ok := cache.Devices().ForName(acv.DeviceName)
 			if !ok {
@@ -55,16 +56,24 @@ func processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 
 				if common.CurrentConfig.Device.DataTransform {
 					err := transformer.TransformReadResult(cv, dr.Properties.Value)
+					if err == nil {
+						err = transformer.TransformReadExpression(cv, dr)
+					}
 					if err != nil {
 						common.LoggingClient.Error(fmt.Sprintf("processAsyncResults - CommandValue (%s) transformed failed: %v", cv.String(), err))
-						cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Transformation failed for device resource, with value: %s, property value: %v, and error: %v", cv.String(), dr.Properties.Value, err))
+						cv = common.MarkCommandValue(cv, dsModels.QualityBad, dsModels.ReasonTransformFailed,
+							fmt.Sprintf("Transformation failed for device resource, with value: %s, property value: %v, and error: %v", cv.String(), dr.Properties.Value, err))
 					}
 				}
 
-				err := transformer.CheckAssertion(cv, dr.Properties.Value.Assertion, &device)
+				drop, err := transformer.CheckAssertion(cv, dr, &device)
 				if err != nil {
 					common.LoggingClient.Error(fmt.Sprintf("processAsyncResults - Assertion failed for device resource: %s, with value: %s and assertion: %s, %v", cv.DeviceResourceName, cv.String(), dr.Properties.Value.Assertion, err))
-					cv = dsModels.NewStringValue(cv.DeviceResourceName, cv.Origin, fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
+					if drop {
+						continue
+					}
+					cv = common.MarkCommandValue(cv, dsModels.QualityBad, dsModels.ReasonAssertionFailed,
+						fmt.Sprintf("Assertion failed for device resource, with value: %s and assertion: %s", cv.String(), dr.Properties.Value.Assertion))
 				}
 
 				ro, err := cache.Profiles().ResourceOperation(device.Profile.Name, cv.DeviceResourceName, common.GetCmdMethod)
@@ -76,17 +85,30 @@ func processAsyncResults(ctx context.Context, wg *sync.WaitGroup) {
 						cv = newCV
 					} else {
 						common.LoggingClient.Warn(fmt.Sprintf("processAsyncResults - Mapping failed for Device Resource Operation: %s, with value: %s, %v", ro.DeviceCommand, cv.String(), err))
+						if common.CurrentConfig.Device.ReadingQuality {
+							cv.SetQuality(dsModels.QualityUncertain, dsModels.ReasonMappingFailed)
+						}
 					}
 				}
 
 				reading := common.CommandValueToReading(cv, device.Name, dr.Properties.Value.MediaType, dr.Properties.Value.FloatEncoding)
 				readings = append(readings, *reading)
+				if !cv.IsGood() {
+					qualities[reading.Name] = dsModels.ReadingQuality{Quality: cv.Quality, Reason: cv.QualityReason}
+				}
+			}
+
+			if len(readings) == 0 {
+				continue
 			}
 
 			// push to Core Data
 			cevent := contract.Event{Device: device.Name, Readings: readings}
 			event := &dsModels.Event{Event: cevent}
 			event.Origin = common.GetUniqueOrigin()
+			for name, quality := range qualities {
+				event.SetQuality(name, quality)
+			}
 			common.SendEvent(event)
 
 		}
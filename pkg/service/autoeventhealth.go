@@ -0,0 +1,44 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+)
+
+// startAutoEventHealth sets the backoff and disabling policy of the
+// AutoEvents whose reads fail repeatedly.
+func startAutoEventHealth() error {
+	config := common.CurrentConfig.Device.AutoEventHealth
+	if config.FailureThreshold < 0 {
+		return fmt.Errorf("AutoEventHealth FailureThreshold %d cannot be negative", config.FailureThreshold)
+	}
+
+	initialBackoff, err := parseOptionalDuration(config.InitialBackoff)
+	if err != nil {
+		return fmt.Errorf("AutoEventHealth InitialBackoff %s cannot be parsed: %v", config.InitialBackoff, err)
+	}
+	maxBackoff, err := parseOptionalDuration(config.MaxBackoff)
+	if err != nil {
+		return fmt.Errorf("AutoEventHealth MaxBackoff %s cannot be parsed: %v", config.MaxBackoff, err)
+	}
+	probeInterval, err := parseOptionalDuration(config.ProbeInterval)
+	if err != nil {
+		return fmt.Errorf("AutoEventHealth ProbeInterval %s cannot be parsed: %v", config.ProbeInterval, err)
+	}
+
+	autoevent.SetHealthPolicy(autoevent.HealthPolicy{
+		InitialBackoff:   initialBackoff,
+		MaxBackoff:       maxBackoff,
+		FailureThreshold: config.FailureThreshold,
+		ProbeInterval:    probeInterval,
+	})
+	return nil
+}
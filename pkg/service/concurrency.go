@@ -0,0 +1,37 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/limiter"
+)
+
+// startDriverLimiter enables the per-device and per-address limits on
+// concurrent driver calls, if any is configured.
+func startDriverLimiter() error {
+	config := common.CurrentConfig.Device.Concurrency
+	if config.MaxPerDevice <= 0 && config.MaxPerAddress <= 0 {
+		common.DriverLimiter = nil
+		return nil
+	}
+	if config.MaxPerAddress > 0 && len(config.AddressProperties) == 0 {
+		return fmt.Errorf("concurrency MaxPerAddress requires AddressProperties to be specified")
+	}
+
+	maxWait, err := parseOptionalDuration(config.MaxWait)
+	if err != nil {
+		return fmt.Errorf("concurrency MaxWait %s cannot be parsed: %v", config.MaxWait, err)
+	}
+
+	common.DriverLimiter = limiter.New(maxWait)
+	common.LoggingClient.Info(fmt.Sprintf("Driver calls limited to %d per device and %d per address", config.MaxPerDevice, config.MaxPerAddress))
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/batcher"
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/eventsink"
+	"github.com/edgexfoundry/device-sdk-go/internal/outbox"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// startEventOutbox opens the persistent event outbox, if enabled by
+// configuration, and starts the forwarder which drains it to Core Data.
+func startEventOutbox(ctx context.Context, wg *sync.WaitGroup) error {
+	config := common.CurrentConfig.Device.Outbox
+	if !config.Enabled {
+		return nil
+	}
+
+	retention, err := parseOptionalDuration(config.Retention)
+	if err != nil {
+		return fmt.Errorf("outbox Retention %s cannot be parsed: %v", config.Retention, err)
+	}
+	blockTimeout, err := parseOptionalDuration(config.BlockTimeout)
+	if err != nil {
+		return fmt.Errorf("outbox BlockTimeout %s cannot be parsed: %v", config.BlockTimeout, err)
+	}
+	retryInterval, err := parseOptionalDuration(config.RetryInterval)
+	if err != nil {
+		return fmt.Errorf("outbox RetryInterval %s cannot be parsed: %v", config.RetryInterval, err)
+	}
+	maxRetryInterval, err := parseOptionalDuration(config.MaxRetryInterval)
+	if err != nil {
+		return fmt.Errorf("outbox MaxRetryInterval %s cannot be parsed: %v", config.MaxRetryInterval, err)
+	}
+
+	ob, err := outbox.Open(outbox.Config{
+		Path:           config.Path,
+		MaxEvents:      config.MaxEvents,
+		MaxBytes:       config.MaxBytes,
+		Retention:      retention,
+		OverflowPolicy: config.OverflowPolicy,
+		BlockTimeout:   blockTimeout,
+	}, common.LoggingClient)
+	if err != nil {
+		return err
+	}
+	common.EventOutbox = ob
+
+	wg.Add(1)
+	go ob.Forward(ctx, wg, common.ForwardEvent, retryInterval, maxRetryInterval)
+	common.LoggingClient.Info(fmt.Sprintf("Event outbox started in %s", config.Path))
+
+	return nil
+}
+
+// startEventBatcher starts the event batcher, if enabled by configuration.
+func startEventBatcher(ctx context.Context, wg *sync.WaitGroup) error {
+	config := common.CurrentConfig.Device.EventBatch
+	if !config.Enabled {
+		return nil
+	}
+
+	maxLatency, err := parseOptionalDuration(config.MaxLatency)
+	if err != nil {
+		return fmt.Errorf("event batch MaxLatency %s cannot be parsed: %v", config.MaxLatency, err)
+	}
+
+	b := batcher.New(batcher.Config{
+		MaxEvents:        config.MaxEvents,
+		MaxBytes:         config.MaxBytes,
+		MaxLatency:       maxLatency,
+		MaxQueuedBatches: config.MaxQueuedBatches,
+	}, common.FlushEventBatch, common.LoggingClient)
+	common.EventBatcher = b
+
+	wg.Add(1)
+	go b.Run(ctx, wg)
+	common.LoggingClient.Info(fmt.Sprintf("Event batching started, flushing every %d events, %d bytes or %v", config.MaxEvents, config.MaxBytes, maxLatency))
+
+	return nil
+}
+
+// startEventSinks registers the event sinks selected by configuration.
+func startEventSinks() error {
+	config := common.CurrentConfig.Device.EventSink
+	names := config.Sinks
+	if len(names) == 0 {
+		names = []string{eventsink.CoreDataSinkName}
+	}
+
+	for _, name := range names {
+		var sink dsModels.EventSink
+		var err error
+		switch name {
+		case eventsink.CoreDataSinkName:
+			sink = eventsink.NewCoreDataSink()
+		case eventsink.FileSinkName:
+			sink, err = eventsink.NewFileSink(config.FilePath)
+		case eventsink.PublisherSinkName:
+			sink, err = eventsink.NewPublisherSink(common.EventPublisher, config.PublisherTopic)
+		default:
+			err = fmt.Errorf("unknown event sink %s", name)
+		}
+		if err != nil {
+			return err
+		}
+		common.AddEventSink(sink)
+		common.LoggingClient.Info(fmt.Sprintf("Event sink %s registered", name))
+	}
+
+	return nil
+}
+
+// closeEventSinks unregisters the event sinks and closes those holding resources.
+func closeEventSinks() {
+	for _, sink := range common.ClearEventSinks() {
+		if closer, ok := sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				common.LoggingClient.Error(fmt.Sprintf("Couldn't close event sink %s: %v", sink.Name(), err))
+			}
+		}
+	}
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
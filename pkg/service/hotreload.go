@@ -0,0 +1,73 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-bootstrap/bootstrap/flags"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/provision"
+)
+
+const defaultHotReloadInterval = 5 * time.Second
+
+// startHotReload starts polling ProfilesDir, DevicesDir and the DeviceList of
+// the local configuration file, if enabled by configuration. devices are the
+// devices loaded at startup.
+func startHotReload(ctx context.Context, wg *sync.WaitGroup, devices []common.DeviceConfig) error {
+	config := common.CurrentConfig.Device.HotReload
+	if !config.Enabled {
+		return nil
+	}
+
+	interval, err := parseOptionalDuration(config.Interval)
+	if err != nil {
+		return fmt.Errorf("hot reload Interval %s cannot be parsed: %v", config.Interval, err)
+	}
+	if interval <= 0 {
+		interval = defaultHotReloadInterval
+	}
+
+	w := provision.NewWatcher(common.CurrentConfig.Device.ProfilesDir, common.CurrentConfig.Device.DevicesDir, configFile, common.CurrentConfig.DeviceList, devices)
+	wg.Add(1)
+	go w.Run(ctx, wg, interval)
+	common.LoggingClient.Info(fmt.Sprintf("Polling %s and %s for changes every %v", common.CurrentConfig.Device.ProfilesDir, configFile, interval))
+
+	return nil
+}
+
+// configFilePath returns the path of the local configuration file, resolved
+// the same way go-mod-bootstrap does when loading it.
+func configFilePath(f *flags.Default) string {
+	dir := f.ConfigDirectory()
+	if env := os.Getenv("EDGEX_CONF_DIR"); env != "" {
+		dir = env
+	}
+	if dir == "" {
+		dir = "./res"
+	}
+
+	profile := f.Profile()
+	if env := os.Getenv("EDGEX_PROFILE"); env != "" {
+		profile = env
+	} else if env := os.Getenv("edgex_profile"); env != "" {
+		profile = env
+	}
+
+	name := f.ConfigFileName()
+	if env := os.Getenv("EDGEX_CONFIG_FILE"); env != "" {
+		name = env
+	}
+	return filepath.Join(dir, profile, name)
+}
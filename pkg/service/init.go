@@ -65,6 +65,42 @@ func (b *Bootstrap) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup, _
 		return false
 	}
 
+	err = startEventOutbox(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't start the event outbox: %v\n", err)
+		return false
+	}
+
+	err = startEventBatcher(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't start the event batcher: %v\n", err)
+		return false
+	}
+
+	err = startEventSinks()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't start the event sinks: %v\n", err)
+		return false
+	}
+
+	err = startDriverLimiter()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't start the driver concurrency limits: %v\n", err)
+		return false
+	}
+
+	err = startAutoEventHealth()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't set the AutoEvent failure handling: %v\n", err)
+		return false
+	}
+
+	err = startAssertions()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't set the assertion failure handling: %v\n", err)
+		return false
+	}
+
 	err = selfRegister()
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Couldn't register to metadata service: %v\n", err)
@@ -87,12 +123,25 @@ func (b *Bootstrap) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup, _
 		return false
 	}
 
-	err = provision.LoadDevices(common.CurrentConfig.DeviceList)
+	devices := provision.DeviceConfigs(common.CurrentConfig.DeviceList, common.CurrentConfig.Device.DevicesDir)
+	err = provision.LoadDevices(devices)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Failed to create the pre-defined Devices: %v\n", err)
 		return false
 	}
 
+	err = startHotReload(ctx, wg, devices)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't start the hot reload of profiles and devices: %v\n", err)
+		return false
+	}
+
+	err = startLiveness(ctx, wg)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't start the liveness monitoring: %v\n", err)
+		return false
+	}
+
 	go autodiscovery.Run()
 	autoevent.GetManager().StartAutoEvents()
 	http.TimeoutHandler(nil, time.Millisecond*time.Duration(common.CurrentConfig.Service.Timeout), "Request timed out")
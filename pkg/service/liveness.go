@@ -0,0 +1,57 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/liveness"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+var livenessMonitor *liveness.Monitor
+
+// startLiveness starts pinging the devices, if enabled by configuration and
+// supported by the driver.
+func startLiveness(ctx context.Context, wg *sync.WaitGroup) error {
+	config := common.CurrentConfig.Device.Liveness
+	if !config.Enabled {
+		return nil
+	}
+	pinger, ok := common.Driver.(dsModels.DevicePinger)
+	if !ok {
+		common.LoggingClient.Warn("Liveness monitoring is enabled but the driver doesn't implement DevicePinger")
+		return nil
+	}
+
+	interval, err := parseOptionalDuration(config.Interval)
+	if err != nil {
+		return fmt.Errorf("liveness Interval %s cannot be parsed: %v", config.Interval, err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("liveness Interval must be specified")
+	}
+	timeout, err := parseOptionalDuration(config.Timeout)
+	if err != nil {
+		return fmt.Errorf("liveness Timeout %s cannot be parsed: %v", config.Timeout, err)
+	}
+
+	livenessMonitor = liveness.NewMonitor(pinger, liveness.Config{
+		Interval:             interval,
+		Timeout:              timeout,
+		FailureThreshold:     config.FailureThreshold,
+		UpdateOperatingState: config.UpdateOperatingState,
+	})
+	wg.Add(1)
+	go livenessMonitor.Run(ctx, wg)
+	common.LoggingClient.Info(fmt.Sprintf("Pinging devices every %v", interval))
+
+	return nil
+}
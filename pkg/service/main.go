@@ -25,19 +25,31 @@ import (
 	"github.com/gorilla/mux"
 )
 
-var instanceName string
+var (
+	instanceName        string
+	validateProfilesDir string
+	configFile          string
+)
 
 func Main(serviceName string, serviceVersion string, proto interface{}, ctx context.Context, cancel context.CancelFunc, router *mux.Router, readyStream chan<- bool) {
 	startupTimer := startup.NewStartUpTimer(common.BootRetrySecondsDefault, common.BootTimeoutSecondsDefault)
 
 	additionalUsage :=
 		"    -i, --instance                  Provides a service name suffix which allows unique instance to be created\n" +
-			"                                    If the option is provided, service name will be replaced with \"<name>_<instance>\"\n"
+			"                                    If the option is provided, service name will be replaced with \"<name>_<instance>\"\n" +
+			"    --validate-profiles <dir>       Validates the device profiles in <dir>, prints a report and exits\n" +
+			"                                    with a non-zero status if any profile is invalid\n"
 	sdkFlags := flags.NewWithUsage(additionalUsage)
 	sdkFlags.FlagSet.StringVar(&instanceName, "instance", "", "")
 	sdkFlags.FlagSet.StringVar(&instanceName, "i", "", "")
+	sdkFlags.FlagSet.StringVar(&validateProfilesDir, "validate-profiles", "", "")
 	sdkFlags.Parse(os.Args[1:])
 
+	if validateProfilesDir != "" {
+		os.Exit(validateProfiles(validateProfilesDir))
+	}
+
+	configFile = configFilePath(sdkFlags)
 	serviceName = setServiceName(serviceName, sdkFlags.Profile())
 	if serviceName == "" {
 		_, _ = fmt.Fprintf(os.Stderr, "Please specify device service name")
@@ -60,6 +72,11 @@ func Main(serviceName string, serviceVersion string, proto interface{}, ctx cont
 	} else {
 		common.Discovery = nil
 	}
+	if publisher, ok := proto.(dsModels.EventPublisher); ok {
+		common.EventPublisher = publisher
+	} else {
+		common.EventPublisher = nil
+	}
 
 	configuration := &common.ConfigurationStruct{}
 	dic := di.NewContainer(di.ServiceConstructorMap{
@@ -0,0 +1,62 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/autoevent"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// PauseAutoEvents pauses the AutoEvents of the specified Device, or of all the
+// Devices if deviceName is empty. The AutoEvents stay paused when the Device is
+// updated, until ResumeAutoEvents is called.
+func (s *Service) PauseAutoEvents(deviceName string) error {
+	if deviceName == "" {
+		autoevent.GetManager().PauseAutoEvents()
+		return nil
+	}
+	if appErr := autoevent.GetManager().PauseForDevice(deviceName); appErr != nil {
+		return errors.New(appErr.Message())
+	}
+	return nil
+}
+
+// ResumeAutoEvents resumes the AutoEvents of the specified Device, or all the
+// AutoEvents if deviceName is empty.
+func (s *Service) ResumeAutoEvents(deviceName string) error {
+	if deviceName == "" {
+		autoevent.GetManager().ResumeAutoEvents()
+		return nil
+	}
+	if appErr := autoevent.GetManager().ResumeForDevice(deviceName); appErr != nil {
+		return errors.New(appErr.Message())
+	}
+	return nil
+}
+
+// AutoEvents reports the state of the AutoEvents of the specified Device, or
+// of all the Devices if deviceName is empty.
+func (s *Service) AutoEvents(deviceName string) ([]dsModels.AutoEventStatus, error) {
+	status, appErr := autoevent.GetManager().Status(deviceName)
+	if appErr != nil {
+		return nil, errors.New(appErr.Message())
+	}
+	return status, nil
+}
+
+// TriggerAutoEvent executes the AutoEvent of the specified Device for the
+// resource immediately, and returns the event published, if any.
+func (s *Service) TriggerAutoEvent(deviceName string, resource string) (*dsModels.Event, error) {
+	event, appErr := autoevent.GetManager().TriggerForDevice(context.Background(), deviceName, resource)
+	if appErr != nil {
+		return nil, errors.New(appErr.Message())
+	}
+	return event, nil
+}
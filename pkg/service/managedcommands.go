@@ -0,0 +1,48 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	"github.com/edgexfoundry/device-sdk-go/internal/handler"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// ReadDevices executes the read command on the operational devices matched
+// by the selector, with the same concurrency limits as /device/all, and
+// returns the result of every device. The events read aren't sent to Core
+// Data. An error is only returned if the selector is invalid.
+func (s *Service) ReadDevices(selector dsModels.DeviceSelector, command string) (dsModels.MultiStatusResponse, error) {
+	return s.commandDevices(selector, command, http.MethodGet, "")
+}
+
+// WriteDevices executes the write command on the operational devices matched
+// by the selector, with the parameters given by device resource name, and
+// returns the result of every device. An error is only returned if the
+// selector is invalid.
+func (s *Service) WriteDevices(selector dsModels.DeviceSelector, command string, params map[string]string) (dsModels.MultiStatusResponse, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return dsModels.MultiStatusResponse{}, err
+	}
+	return s.commandDevices(selector, command, http.MethodPut, string(body))
+}
+
+func (s *Service) commandDevices(selector dsModels.DeviceSelector, command string, method string, body string) (dsModels.MultiStatusResponse, error) {
+	ctx, cancel := common.NewCommandContext(context.Background(), "")
+	defer cancel()
+	results, appErr := handler.SelectorCommandHandler(ctx, selector, command, body, method, "")
+	if appErr != nil && results == nil {
+		return dsModels.MultiStatusResponse{}, errors.New(appErr.Message())
+	}
+	return handler.NewMultiStatusResponse(results), nil
+}
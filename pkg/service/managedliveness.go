@@ -0,0 +1,31 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/cache"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// DeviceLiveness reports the reachability of the specified Device as tracked by
+// the liveness monitor. An error is returned if the monitoring isn't enabled,
+// the Device doesn't exist or hasn't been pinged yet.
+func (s *Service) DeviceLiveness(deviceName string) (dsModels.DeviceLiveness, error) {
+	if livenessMonitor == nil {
+		return dsModels.DeviceLiveness{}, fmt.Errorf("liveness monitoring isn't enabled")
+	}
+	if _, ok := cache.Devices().ForName(deviceName); !ok {
+		return dsModels.DeviceLiveness{}, fmt.Errorf("device %s cannot be found in cache", deviceName)
+	}
+	liveness, ok := livenessMonitor.Liveness(deviceName)
+	if !ok {
+		return liveness, fmt.Errorf("device %s hasn't been pinged yet", deviceName)
+	}
+	return liveness, nil
+}
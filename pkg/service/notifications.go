@@ -0,0 +1,26 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"github.com/edgexfoundry/device-sdk-go/internal/common"
+	dsModels "github.com/edgexfoundry/device-sdk-go/pkg/models"
+)
+
+// Subscribe returns a channel receiving the notifications of the changes of the
+// Devices, DeviceProfiles and ProvisionWatchers of the Device Service, whatever
+// their origin: Core Metadata callbacks, the Service API, hot reload, or the
+// Device Service itself disabling a Device. The liveness monitor also reports
+// the Devices becoming unreachable or reachable again. Only the notifications of the given
+// types are sent, or all of them if no type is given.
+//
+// Notifications are never blocking: they are dropped, with a warning, when the
+// channel buffer of bufferSize is full. The returned function cancels the
+// subscription and closes the channel.
+func (s *Service) Subscribe(bufferSize int, types ...dsModels.NotificationType) (<-chan dsModels.Notification, func()) {
+	return common.Subscribe(bufferSize, types...)
+}
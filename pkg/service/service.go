@@ -63,12 +63,20 @@ func (s *Service) AddRoute(route string, handler func(http.ResponseWriter, *http
 	return s.controller.AddRoute(route, handler, methods...)
 }
 
-// Stop shuts down the Service
+// Stop shuts down the Service. The AutoEvents are stopped, and their reads in
+// progress completed, before the driver is stopped.
 func (s *Service) Stop(force bool) {
+	autoevent.GetManager().StopAutoEvents()
 	if s.initiazlied {
 		_ = common.Driver.Stop(force)
 	}
-	autoevent.GetManager().StopAutoEvents()
+	closeEventSinks()
+}
+
+// AddEventSink registers an additional destination for the events generated
+// by the Device Service, alongside the sinks selected in configuration.
+func (s *Service) AddEventSink(sink dsModels.EventSink) {
+	common.AddEventSink(sink)
 }
 
 // selfRegister register device service itself onto metadata.
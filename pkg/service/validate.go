@@ -0,0 +1,42 @@
+// -*- Mode: Go; indent-tabs-mode: t -*-
+//
+// Copyright (C) 2020 IOTech Ltd
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/edgexfoundry/device-sdk-go/internal/validator"
+)
+
+// validateProfiles prints the validation report of the device profiles in dir
+// and returns the exit status of the --validate-profiles mode.
+func validateProfiles(dir string) int {
+	reports, err := validator.ValidateDir(dir)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Couldn't read the device profiles in %s: %v\n", dir, err)
+		return 2
+	}
+	if len(reports) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "No device profiles found in %s\n", dir)
+		return 2
+	}
+
+	invalid := 0
+	for _, r := range reports {
+		fmt.Println(r.String())
+		if !r.Valid {
+			invalid++
+		}
+	}
+	fmt.Printf("%d of %d device profiles valid\n", len(reports)-invalid, len(reports))
+
+	if invalid > 0 {
+		return 1
+	}
+	return 0
+}